@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Secret untuk menandatangani access token (HMAC-SHA256), diisi LoadJWTSecret saat server start.
+var JWTSecret []byte
+
+// Panjang minimal JWT_SECRET (32 byte = ukuran kunci HMAC-SHA256)
+const minJWTSecretLength = 32
+
+// LoadJWTSecret membaca JWT_SECRET dari environment variable. Tidak ada nilai default:
+// siapa pun yang tahu secret bisa membuat token admin sendiri, jadi server menolak jalan
+// jika secret kosong atau terlalu pendek.
+func LoadJWTSecret() {
+	secret := os.Getenv("JWT_SECRET")
+	if len(secret) < minJWTSecretLength {
+		panic(fmt.Sprintf("JWT_SECRET wajib diisi minimal %d karakter (contoh: openssl rand -base64 48)", minJWTSecretLength))
+	}
+	JWTSecret = []byte(secret)
+}
+
+// Masa berlaku access token
+var AccessTokenTTL = 15 * time.Minute
+
+// Masa berlaku refresh token (sesi login)
+var RefreshTokenTTL = 30 * 24 * time.Hour
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Masa berlaku link reset password
+var PasswordResetTTL = 1 * time.Hour
+
+// Halaman frontend untuk form reset password, token ditambahkan sebagai ?token=...
+var PasswordResetURL = getEnv("PASSWORD_RESET_URL", "https://bookthree.miproduction.my.id/reset-password")
+
+// Jika server berada di belakang reverse proxy (nginx), IP asli client diambil dari header X-Real-IP.
+// Jangan aktifkan jika server diakses langsung, karena header tersebut bisa dipalsukan.
+var TrustProxy = getEnv("TRUST_PROXY", "false") == "true"
@@ -0,0 +1,322 @@
+package config
+
+import (
+	"be/models"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Satu langkah migrasi. Setiap langkah harus aman dijalankan berulang kali
+// (idempotent), karena Migrate() dipanggil setiap server start.
+type migration func() error
+
+// Daftar perubahan skema database, dijalankan berurutan.
+var migrations = []migration{
+	// Hash bcrypt panjangnya 60 karakter, perbesar kolom password
+	execSQL(`ALTER TABLE users MODIFY password VARCHAR(255) NOT NULL`),
+	addColumn("users", "is_disabled", "TINYINT(1) NOT NULL DEFAULT 0"),
+	addColumn("users", "created_at", "DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP"),
+
+	// Akun customer
+	addColumn("users", "full_name", "VARCHAR(150) NULL"),
+	addColumn("users", "email", "VARCHAR(150) NULL"),
+	addColumn("users", "phone", "VARCHAR(30) NULL"),
+
+	// Username & email wajib unik (registrasi bersamaan tidak boleh membuat akun ganda).
+	// Duplikat lama: akun yang lebih baru diberi username "nama#id" dan email-nya dikosongkan.
+	dedupeColumn("users", "username", "uniq_users_username", "CONCAT(t.username, '#', t.id)"),
+	addIndex("users", "uniq_users_username", "UNIQUE INDEX uniq_users_username (username)"),
+	dedupeColumn("users", "email", "uniq_users_email", "NULL"),
+	addIndex("users", "uniq_users_email", "UNIQUE INDEX uniq_users_email (email)"),
+	execSQL(`CREATE TABLE IF NOT EXISTS customer_addresses (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		user_id INT NOT NULL,
+		label VARCHAR(50) NOT NULL DEFAULT '',
+		recipient_name VARCHAR(150) NOT NULL,
+		phone VARCHAR(30) NOT NULL,
+		address TEXT NOT NULL,
+		is_default TINYINT(1) NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_customer_addresses_user (user_id)
+	)`),
+	addColumn("transactions", "customer_id", "INT NULL"),
+
+	// Sesi login (refresh token), yang disimpan hanya hash-nya
+	execSQL(`CREATE TABLE IF NOT EXISTS refresh_tokens (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		user_id INT NOT NULL,
+		token_hash CHAR(64) NOT NULL,
+		expires_at DATETIME NOT NULL,
+		revoked_at DATETIME NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE INDEX uniq_refresh_tokens_hash (token_hash),
+		INDEX idx_refresh_tokens_user (user_id)
+	)`),
+
+	// Token reset password (sekali pakai), yang disimpan hanya hash-nya
+	execSQL(`CREATE TABLE IF NOT EXISTS password_resets (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		user_id INT NOT NULL,
+		token_hash CHAR(64) NOT NULL,
+		expires_at DATETIME NOT NULL,
+		used_at DATETIME NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE INDEX uniq_password_resets_hash (token_hash),
+		INDEX idx_password_resets_user (user_id)
+	)`),
+
+	// Order code wajib unik (generator akan retry jika bentrok).
+	// Generator lama bisa menghasilkan kode kembar di hari yang sama: pesanan yang lebih baru
+	// diberi akhiran "_id" (karakter "_" tidak ada di format baru, jadi tidak dianggap salah check digit).
+	dedupeColumn("transactions", "order_code", "uniq_transactions_order_code", "CONCAT(t.order_code, '_', t.id)"),
+	addIndex("transactions", "uniq_transactions_order_code", "UNIQUE INDEX uniq_transactions_order_code (order_code)"),
+
+	// Riwayat perubahan status pesanan (untuk audit & tracking customer)
+	execSQL(`CREATE TABLE IF NOT EXISTS transaction_status_history (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		transaction_id INT NOT NULL,
+		from_status INT NULL,
+		to_status INT NOT NULL,
+		changed_by INT NULL,
+		note VARCHAR(255) NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_status_history_transaction (transaction_id)
+	)`),
+
+	// Penanda stok pesanan sudah dikembalikan (agar tidak dikembalikan dua kali)
+	addColumn("transactions", "stock_restored", "TINYINT(1) NOT NULL DEFAULT 0"),
+
+	// Snapshot data buku saat dibeli, agar riwayat pesanan tidak berubah
+	// walaupun buku diubah / dihapus
+	addColumn("transaction_details", "book_title", "VARCHAR(255) NULL"),
+	addColumn("transaction_details", "book_author", "VARCHAR(255) NULL"),
+	addColumn("transaction_details", "book_image", "VARCHAR(500) NULL"),
+	execSQL(`UPDATE transaction_details td
+		JOIN books b ON td.book_id = b.id
+		SET td.book_title = b.title, td.book_author = b.author, td.book_image = b.image_url
+		WHERE td.book_title IS NULL`),
+
+	// Soft delete buku (arsip)
+	addColumn("books", "deleted_at", "DATETIME NULL"),
+	addIndex("books", "idx_books_deleted_at", "INDEX idx_books_deleted_at (deleted_at)"),
+
+	// Kategori ter-normalisasi (dengan hierarki) + relasi many-to-many ke buku
+	execSQL(`CREATE TABLE IF NOT EXISTS categories (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		name VARCHAR(100) NOT NULL,
+		slug VARCHAR(120) NOT NULL,
+		parent_id INT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE INDEX uniq_categories_slug (slug),
+		INDEX idx_categories_parent (parent_id)
+	)`),
+	execSQL(`CREATE TABLE IF NOT EXISTS book_categories (
+		book_id INT NOT NULL,
+		category_id INT NOT NULL,
+		position INT NOT NULL DEFAULT 0,
+		PRIMARY KEY (book_id, category_id),
+		INDEX idx_book_categories_category (category_id)
+	)`),
+	migrateLegacyCategories,
+
+	// Penulis sebagai entitas sendiri + relasi many-to-many ke buku
+	execSQL(`CREATE TABLE IF NOT EXISTS authors (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		name VARCHAR(150) NOT NULL,
+		bio TEXT NULL,
+		photo_url VARCHAR(255) NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_authors_name (name)
+	)`),
+	execSQL(`CREATE TABLE IF NOT EXISTS book_authors (
+		book_id INT NOT NULL,
+		author_id INT NOT NULL,
+		position INT NOT NULL DEFAULT 0,
+		PRIMARY KEY (book_id, author_id),
+		INDEX idx_book_authors_author (author_id)
+	)`),
+	migrateLegacyAuthors,
+
+	// Data bibliografi buku
+	addColumn("books", "isbn", "VARCHAR(13) NULL"),
+	addIndex("books", "uniq_books_isbn", "UNIQUE INDEX uniq_books_isbn (isbn)"),
+	addColumn("books", "publisher", "VARCHAR(150) NOT NULL DEFAULT ''"),
+	addColumn("books", "publication_year", "INT NULL"),
+	addColumn("books", "page_count", "INT NULL"),
+	addColumn("books", "language", "VARCHAR(50) NOT NULL DEFAULT ''"),
+	addColumn("books", "format", "VARCHAR(20) NOT NULL DEFAULT ''"),
+	addColumn("books", "weight_grams", "INT NULL"),
+}
+
+// migrateLegacyCategories memindahkan isi kolom books.category (teks bebas) ke tabel categories.
+// Hanya buku yang belum punya kategori di book_categories yang diproses. Kategori yang sudah ada
+// dicari lewat nama (tanpa beda huruf besar/kecil) atau slug, karena slug bisa sudah diubah admin.
+func migrateLegacyCategories() error {
+	rows, err := DB.Query(`SELECT b.id, b.category FROM books b
+		WHERE b.category IS NOT NULL AND TRIM(b.category) <> ''
+		AND NOT EXISTS (SELECT 1 FROM book_categories bc WHERE bc.book_id = b.id)`)
+	if err != nil {
+		return err
+	}
+	books := map[int]string{}
+	for rows.Next() {
+		var id int
+		var category string
+		if err := rows.Scan(&id, &category); err != nil {
+			rows.Close()
+			return err
+		}
+		books[id] = strings.TrimSpace(category)
+	}
+	rows.Close()
+
+	for bookID, name := range books {
+		slug := models.Slugify(name)
+		if slug == "" {
+			continue
+		}
+
+		var categoryID int64
+		err := DB.QueryRow(`SELECT id FROM categories WHERE LOWER(name) = LOWER(?) OR slug = ?
+			ORDER BY LOWER(name) = LOWER(?) DESC, id LIMIT 1`, name, slug, name).Scan(&categoryID)
+		if err == sql.ErrNoRows {
+			result, err := DB.Exec("INSERT INTO categories (name, slug) VALUES (?, ?)", name, slug)
+			if err != nil {
+				return err
+			}
+			categoryID, _ = result.LastInsertId()
+		} else if err != nil {
+			return err
+		}
+
+		if _, err := DB.Exec("INSERT IGNORE INTO book_categories (book_id, category_id) VALUES (?, ?)", bookID, categoryID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateLegacyAuthors memindahkan isi kolom books.author (teks, "A, B") ke tabel authors.
+// Hanya buku yang belum punya penulis di book_authors yang diproses, dan nama yang sama
+// (tanpa beda huruf besar/kecil) memakai baris penulis yang sama.
+func migrateLegacyAuthors() error {
+	rows, err := DB.Query(`SELECT b.id, b.author FROM books b
+		WHERE TRIM(b.author) <> ''
+		AND NOT EXISTS (SELECT 1 FROM book_authors ba WHERE ba.book_id = b.id)`)
+	if err != nil {
+		return err
+	}
+	books := map[int]string{}
+	for rows.Next() {
+		var id int
+		var author string
+		if err := rows.Scan(&id, &author); err != nil {
+			rows.Close()
+			return err
+		}
+		books[id] = author
+	}
+	rows.Close()
+
+	for bookID, author := range books {
+		for position, name := range models.SplitAuthorNames(author) {
+			var authorID int64
+			err := DB.QueryRow("SELECT id FROM authors WHERE LOWER(name) = LOWER(?) ORDER BY id LIMIT 1", name).Scan(&authorID)
+			if err == sql.ErrNoRows {
+				result, err := DB.Exec("INSERT INTO authors (name) VALUES (?)", name)
+				if err != nil {
+					return err
+				}
+				authorID, _ = result.LastInsertId()
+			} else if err != nil {
+				return err
+			}
+
+			if _, err := DB.Exec("INSERT IGNORE INTO book_authors (book_id, author_id, position) VALUES (?, ?, ?)", bookID, authorID, position); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Migrate menjalankan semua perubahan skema di atas.
+func Migrate() {
+	for i, step := range migrations {
+		if err := step(); err != nil {
+			panic(fmt.Sprintf("Migrasi #%d gagal: %v", i+1, err))
+		}
+	}
+
+	fmt.Println("Database Migrated Successfully!")
+}
+
+func execSQL(query string) migration {
+	return func() error {
+		_, err := DB.Exec(query)
+		return err
+	}
+}
+
+// addColumn menambah kolom hanya jika kolom tersebut belum ada.
+func addColumn(table, column, definition string) migration {
+	return func() error {
+		var count int
+		err := DB.QueryRow(`SELECT COUNT(*) FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?`, table, column).Scan(&count)
+		if err != nil || count > 0 {
+			return err
+		}
+		_, err = DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+		return err
+	}
+}
+
+func indexExists(table, index string) (bool, error) {
+	var count int
+	err := DB.QueryRow(`SELECT COUNT(*) FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ?`, table, index).Scan(&count)
+	return count > 0, err
+}
+
+// addIndex menambah index hanya jika index dengan nama tersebut belum ada.
+// definition contoh: "UNIQUE INDEX uniq_x (kolom)"
+func addIndex(table, index, definition string) migration {
+	return func() error {
+		exists, err := indexExists(table, index)
+		if err != nil || exists {
+			return err
+		}
+		_, err = DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD %s", table, definition))
+		return err
+	}
+}
+
+// dedupeColumn dijalankan sebelum addIndex UNIQUE: nilai kolom yang dobel diganti pada baris
+// yang lebih baru (baris terlama tetap), agar ALTER TABLE tidak gagal dan server tetap bisa start.
+// replacement adalah ekspresi SQL nilai baru, baris yang diubah bernama t (contoh: "CONCAT(t.kolom, '-', t.id)").
+// Dilewati jika index sudah ada (berarti data sudah pasti unik).
+func dedupeColumn(table, column, index, replacement string) migration {
+	return func() error {
+		exists, err := indexExists(table, index)
+		if err != nil || exists {
+			return err
+		}
+
+		// DISTINCT membuat subquery di-materialize, sehingga MySQL mengizinkan UPDATE tabel yang sama
+		result, err := DB.Exec(fmt.Sprintf(`UPDATE %[1]s t JOIN (
+				SELECT DISTINCT newer.id FROM %[1]s newer
+				JOIN %[1]s older ON older.%[2]s = newer.%[2]s AND older.id < newer.id
+			) dup ON dup.id = t.id
+			SET t.%[2]s = %[3]s`, table, column, replacement))
+		if err != nil {
+			return err
+		}
+
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			fmt.Printf("Migrasi: %d nilai %s.%s yang dobel diubah sebelum menambah %s\n", affected, table, column, index)
+		}
+		return nil
+	}
+}
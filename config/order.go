@@ -0,0 +1,20 @@
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+// Pesanan yang belum dibayar lebih lama dari ini otomatis dibatalkan (env ORDER_EXPIRY_HOURS)
+var OrderExpiryWindow = time.Duration(getEnvInt("ORDER_EXPIRY_HOURS", 24)) * time.Hour
+
+// Seberapa sering worker mengecek pesanan kedaluwarsa
+var OrderExpiryInterval = 5 * time.Minute
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
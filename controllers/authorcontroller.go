@@ -0,0 +1,399 @@
+package controllers
+
+import (
+	"be/config"
+	"be/models"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 1. LIST & CREATE (URL: /api/authors?q=&page=&limit=)
+func AuthorsHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		getAuthors(w, r)
+	case "POST":
+		createAuthor(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// 2. GET DETAIL, UPDATE, DELETE, BUKU PENULIS (URL: /api/authors/{id}, /api/authors/{id}/books)
+func AuthorDetailHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	id, action, ok := parseIDPath(r.URL.Path, "/api/authors/")
+	if !ok {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == "GET":
+		getAuthor(w, id)
+	case action == "" && r.Method == "PUT":
+		updateAuthor(w, r, id)
+	case action == "" && r.Method == "DELETE":
+		deleteAuthor(w, id)
+	case action == "books" && r.Method == "GET":
+		getAuthorBooks(w, r, id)
+	case action != "" && action != "books":
+		http.NotFound(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Kolom SELECT standar penulis + jumlah buku aktif, urutannya harus sama dengan scanAuthor
+const authorColumns = `a.id, a.name, IFNULL(a.bio, ''), a.photo_url,
+	(SELECT COUNT(*) FROM book_authors ba JOIN books b ON b.id = ba.book_id
+	 WHERE ba.author_id = a.id AND b.deleted_at IS NULL)`
+
+func scanAuthor(scanner interface{ Scan(...interface{}) error }) (models.Author, error) {
+	var author models.Author
+	err := scanner.Scan(&author.ID, &author.Name, &author.Bio, &author.PhotoURL, &author.BookCount)
+	return author, err
+}
+
+func getAuthors(w http.ResponseWriter, r *http.Request) {
+	where := ""
+	var args []interface{}
+	if search := strings.TrimSpace(r.URL.Query().Get("q")); search != "" {
+		where = "WHERE a.name LIKE ?"
+		args = append(args, likeContains(search))
+	}
+
+	page, limit := parsePagination(r, 50, 100)
+
+	var total int
+	if err := config.DB.QueryRow("SELECT COUNT(*) FROM authors a "+where, args...).Scan(&total); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := config.DB.Query("SELECT "+authorColumns+" FROM authors a "+where+" ORDER BY a.name ASC, a.id ASC LIMIT ? OFFSET ?",
+		append(args, limit, (page-1)*limit)...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	authors := []models.Author{}
+	for rows.Next() {
+		author, err := scanAuthor(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		authors = append(authors, author)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.PagedResponse{
+		Data: authors,
+		Meta: newPageMeta(page, limit, total),
+	})
+}
+
+func getAuthor(w http.ResponseWriter, id int) {
+	author, err := scanAuthor(config.DB.QueryRow("SELECT "+authorColumns+" FROM authors a WHERE a.id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Author not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(author)
+}
+
+// getAuthorBooks memakai katalog biasa (filter, sort, pagination sama) dengan filter author_id
+func getAuthorBooks(w http.ResponseWriter, r *http.Request, id int) {
+	var exists int
+	if err := config.DB.QueryRow("SELECT COUNT(*) FROM authors WHERE id = ?", id).Scan(&exists); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if exists == 0 {
+		http.Error(w, "Author not found", http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	q.Set("author_id", strconv.Itoa(id))
+	r.URL.RawQuery = q.Encode()
+	getBooks(w, r)
+}
+
+func createAuthor(w http.ResponseWriter, r *http.Request) {
+	var author models.Author
+	if err := json.NewDecoder(r.Body).Decode(&author); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	author.Name = strings.Join(strings.Fields(author.Name), " ")
+	if author.Name == "" {
+		http.Error(w, "Nama penulis wajib diisi", http.StatusBadRequest)
+		return
+	}
+
+	result, err := config.DB.Exec("INSERT INTO authors (name, bio, photo_url) VALUES (?, ?, ?)", author.Name, author.Bio, author.PhotoURL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	author.ID = int(id)
+	author.BookCount = 0
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(author)
+}
+
+func updateAuthor(w http.ResponseWriter, r *http.Request, id int) {
+	var author models.Author
+	if err := json.NewDecoder(r.Body).Decode(&author); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	author.Name = strings.Join(strings.Fields(author.Name), " ")
+	if author.Name == "" {
+		http.Error(w, "Nama penulis wajib diisi", http.StatusBadRequest)
+		return
+	}
+
+	// Ambil data lama (untuk cek keberadaan & foto lama)
+	var oldName, oldPhotoURL string
+	err := config.DB.QueryRow("SELECT name, photo_url FROM authors WHERE id = ?", id).Scan(&oldName, &oldPhotoURL)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Author not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = config.DB.Exec("UPDATE authors SET name=?, bio=?, photo_url=? WHERE id=?", author.Name, author.Bio, author.PhotoURL, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Foto diganti / dihapus -> hapus file lama
+	if oldPhotoURL != "" && author.PhotoURL != oldPhotoURL {
+		deleteImage(oldPhotoURL)
+	}
+
+	// Nama berubah -> perbarui kolom books.author & index pencarian
+	if author.Name != oldName {
+		authorRelation.refreshBooks(id)
+	}
+
+	author.ID = id
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(author)
+}
+
+func deleteAuthor(w http.ResponseWriter, id int) {
+	var photoURL string
+	err := config.DB.QueryRow("SELECT photo_url FROM authors WHERE id = ?", id).Scan(&photoURL)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Author not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bookIDs, err := authorRelation.bookIDs(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := config.DB.Exec("DELETE FROM authors WHERE id=?", id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := config.DB.Exec("DELETE FROM book_authors WHERE author_id=?", id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if photoURL != "" {
+		deleteImage(photoURL)
+	}
+
+	authorRelation.syncBooks(bookIDs)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Author deleted successfully"})
+}
+
+// --- RELASI BUKU <-> PENULIS ---
+
+// syncBookAuthorNames mengisi kolom lama books.author dengan nama semua penulis ("A, B"),
+// agar frontend lama, pencarian, dan snapshot checkout tetap memakai teks penulis.
+func syncBookAuthorNames(q querier, bookID int) error {
+	_, err := q.Exec(`
+		UPDATE books SET author = IFNULL((
+			SELECT GROUP_CONCAT(a.name ORDER BY ba.position ASC, a.id ASC SEPARATOR ', ')
+			FROM book_authors ba
+			JOIN authors a ON a.id = ba.author_id
+			WHERE ba.book_id = ?
+		), '')
+		WHERE id = ?`, bookID, bookID)
+	return err
+}
+
+// resolveBookAuthors menentukan penulis buku dari input. Jika book.AuthorIDs dikirim,
+// itu yang dipakai (harus ada semua). Jika tidak, teks book.Author ("A, B") dicocokkan
+// dengan nama penulis (dibuat jika belum ada), agar frontend lama tetap berfungsi.
+// bookID diisi saat edit (0 saat buku baru).
+func resolveBookAuthors(q querier, book *models.Book, bookID int) ([]int, int, string) {
+	if book.AuthorIDs != nil {
+		return authorRelation.validateIDs(q, book.AuthorIDs)
+	}
+
+	// Penulis yang sudah terhubung ke buku ini (nama huruf kecil -> id)
+	linked := map[string]int{}
+	if bookID != 0 {
+		// Form lama mengirim balik teks penulis apa adanya: jika tidak diubah, relasi tetap
+		// (nama penulis bisa kembar, dan nama bisa mengandung koma seperti "Andrea Hirata, M.Sc.")
+		var current string
+		if err := q.QueryRow("SELECT author FROM books WHERE id = ?", bookID).Scan(&current); err != nil {
+			return nil, http.StatusInternalServerError, err.Error()
+		}
+		if strings.EqualFold(collapseSpaces(book.Author), collapseSpaces(current)) {
+			ids, err := authorRelation.currentIDs(q, bookID)
+			if err != nil {
+				return nil, http.StatusInternalServerError, err.Error()
+			}
+			if len(ids) > 0 {
+				return ids, 0, ""
+			}
+		}
+
+		rows, err := q.Query(`SELECT a.id, a.name FROM book_authors ba
+			JOIN authors a ON a.id = ba.author_id WHERE ba.book_id = ?`, bookID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err.Error()
+		}
+		for rows.Next() {
+			var id int
+			var name string
+			if err := rows.Scan(&id, &name); err != nil {
+				rows.Close()
+				return nil, http.StatusInternalServerError, err.Error()
+			}
+			linked[strings.ToLower(collapseSpaces(name))] = id
+		}
+		rows.Close()
+	}
+
+	// Potongan teks dipisah koma. Beberapa potongan berurutan yang sama dengan nama penulis
+	// yang sudah terhubung dipakai utuh (cocok terpanjang), sisanya dicari lewat nama.
+	var parts []string
+	for _, part := range strings.Split(book.Author, ",") {
+		if part = collapseSpaces(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	ids := []int{}
+	for i := 0; i < len(parts); {
+		matched := 0
+		for j := len(parts); j > i; j-- {
+			if id, ok := linked[strings.ToLower(strings.Join(parts[i:j], ", "))]; ok {
+				ids = append(ids, id)
+				matched = j - i
+				break
+			}
+		}
+		if matched > 0 {
+			i += matched
+			continue
+		}
+
+		name := parts[i]
+		var id int
+		err := q.QueryRow("SELECT id FROM authors WHERE LOWER(name) = LOWER(?) ORDER BY id LIMIT 1", name).Scan(&id)
+		if err == sql.ErrNoRows {
+			result, err := q.Exec("INSERT INTO authors (name) VALUES (?)", name)
+			if err != nil {
+				return nil, http.StatusInternalServerError, err.Error()
+			}
+			newID, _ := result.LastInsertId()
+			id = int(newID)
+		} else if err != nil {
+			return nil, http.StatusInternalServerError, err.Error()
+		}
+		ids = append(ids, id)
+		i++
+	}
+	return uniqueInts(ids), 0, ""
+}
+
+// collapseSpaces merapikan spasi berlebih ("  Andrea   Hirata " -> "Andrea Hirata").
+func collapseSpaces(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// attachBookAuthors mengisi Authors setiap buku dengan satu query batch.
+func attachBookAuthors(books []models.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	placeholders, args := inClause(bookIDList(books))
+	rows, err := config.DB.Query(`
+		SELECT ba.book_id, a.id, a.name, IFNULL(a.bio, ''), a.photo_url
+		FROM book_authors ba
+		JOIN authors a ON a.id = ba.author_id
+		WHERE ba.book_id IN (`+placeholders+`)
+		ORDER BY ba.position ASC, a.id ASC`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	byBook := map[int][]models.Author{}
+	for rows.Next() {
+		var bookID int
+		var a models.Author
+		if err := rows.Scan(&bookID, &a.ID, &a.Name, &a.Bio, &a.PhotoURL); err != nil {
+			return err
+		}
+		byBook[bookID] = append(byBook[bookID], a)
+	}
+
+	for i := range books {
+		books[i].Authors = byBook[books[i].ID]
+		if books[i].Authors == nil {
+			books[i].Authors = []models.Author{}
+		}
+	}
+	return rows.Err()
+}
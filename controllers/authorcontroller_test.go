@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"be/config"
+	"be/models"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Edit lewat form lama tanpa author_ids, teks penulis tidak diubah: relasi tetap ke penulis yang sama,
+// walau ada penulis lain bernama sama (id lebih kecil) dan nama mengandung koma.
+func TestResolveBookAuthorsKeepsLinksWhenTextUnchanged(t *testing.T) {
+	mock := mockDB(t)
+	mock.ExpectQuery("SELECT author FROM books WHERE id = \\?").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"author"}).AddRow("Andrea Hirata, M.Sc., Ahmad Fuadi"))
+	mock.ExpectQuery("SELECT author_id FROM book_authors WHERE book_id = \\?").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(12).AddRow(20))
+
+	ids, status, msg := resolveBookAuthors(config.DB, &models.Book{Author: " andrea hirata, M.Sc.,  Ahmad Fuadi"}, 5)
+	if msg != "" {
+		t.Fatalf("resolveBookAuthors: %d %s", status, msg)
+	}
+	if want := []int{12, 20}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+}
+
+// Teks penulis diubah: penulis yang sudah terhubung tetap dipakai (termasuk nama berkoma),
+// hanya nama yang benar-benar baru yang dicari / dibuat.
+func TestResolveBookAuthorsOnlyResolvesNewNames(t *testing.T) {
+	mock := mockDB(t)
+	mock.ExpectQuery("SELECT author FROM books WHERE id = \\?").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"author"}).AddRow("Andrea Hirata, M.Sc., Ahmad Fuadi"))
+	mock.ExpectQuery("FROM book_authors ba\\s+JOIN authors a").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(12, "Andrea Hirata, M.Sc.").AddRow(20, "Ahmad Fuadi"))
+	mock.ExpectQuery("SELECT id FROM authors WHERE LOWER\\(name\\)").WithArgs("Tere Liye").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	book := &models.Book{Author: "Ahmad Fuadi, Andrea Hirata, M.Sc., Tere Liye"}
+	ids, status, msg := resolveBookAuthors(config.DB, book, 5)
+	if msg != "" {
+		t.Fatalf("resolveBookAuthors: %d %s", status, msg)
+	}
+	if want := []int{20, 12, 7}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+}
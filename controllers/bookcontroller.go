@@ -3,6 +3,7 @@ package controllers
 import (
 	"be/config"
 	"be/models"
+	"be/utils"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Helper untuk mengatur Header CORS (Agar React bisa akses)
@@ -18,6 +20,7 @@ func enableCors(w *http.ResponseWriter) {
 	(*w).Header().Set("Access-Control-Allow-Origin", "*")
 	(*w).Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
 	(*w).Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+	(*w).Header().Set("Access-Control-Expose-Headers", "X-Total-Count, X-Page, X-Limit, X-Total-Pages")
 }
 
 // 1. GET ALL & CREATE (URL: /api/books)
@@ -37,7 +40,7 @@ func BooksHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// 2. GET DETAIL, UPDATE, DELETE (URL: /api/books/{id})
+// 2. GET DETAIL, UPDATE, ARSIP, RESTORE (URL: /api/books/{id}, /api/books/{id}/restore)
 func BookDetailHandler(w http.ResponseWriter, r *http.Request) {
 	enableCors(&w)
 	if r.Method == "OPTIONS" {
@@ -45,20 +48,23 @@ func BookDetailHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Ambil ID dari URL
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/books/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
+	id, action, ok := parseIDPath(r.URL.Path, "/api/books/")
+	if !ok {
 		http.Error(w, "Invalid ID", http.StatusBadRequest)
 		return
 	}
 
-	switch r.Method {
-	case "GET":
-		getBook(w, id)
-	case "PUT":
+	switch {
+	case action == "" && r.Method == "GET":
+		getBook(w, r, id)
+	case action == "" && r.Method == "PUT":
 		updateBook(w, r, id)
-	case "DELETE":
+	case action == "" && r.Method == "DELETE":
 		deleteBook(w, id)
+	case action == "restore" && r.Method == "POST":
+		restoreBook(w, id)
+	case action != "" && action != "restore":
+		http.NotFound(w, r)
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
@@ -66,34 +72,252 @@ func BookDetailHandler(w http.ResponseWriter, r *http.Request) {
 
 // --- LOGIC IMPLEMENTATION ---
 
+// Kolom SELECT standar buku, urutannya harus sama dengan scanBook
+const bookColumns = "id, title, author, price, category, stock, image_url, description, deleted_at, " +
+	"isbn, publisher, publication_year, page_count, language, format, weight_grams"
+
+// scanBook membaca satu baris hasil SELECT bookColumns (dari *sql.Row atau *sql.Rows)
+func scanBook(scanner interface{ Scan(...interface{}) error }) (models.Book, error) {
+	var book models.Book
+	var deletedAt sql.NullTime
+	var isbn sql.NullString
+	var year, pages, weight sql.NullInt64
+
+	err := scanner.Scan(&book.ID, &book.Title, &book.Author, &book.Price, &book.Category, &book.Stock, &book.ImageURL, &book.Description, &deletedAt,
+		&isbn, &book.Publisher, &year, &pages, &book.Language, &book.Format, &weight)
+	if deletedAt.Valid {
+		book.DeletedAt = &deletedAt.Time
+	}
+	book.ISBN = isbn.String
+	book.PublicationYear = nullIntPtr(year)
+	book.PageCount = nullIntPtr(pages)
+	book.WeightGrams = nullIntPtr(weight)
+	return book, err
+}
+
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int64)
+	return &n
+}
+
+// validateBookMetadata merapikan & mengecek data bibliografi sebelum disimpan.
+// ISBN-10 / ISBN-13 dicek checksum-nya lalu disimpan sebagai ISBN-13.
+func validateBookMetadata(book *models.Book) string {
+	if strings.TrimSpace(book.ISBN) != "" {
+		isbn, ok := utils.NormalizeISBN(book.ISBN)
+		if !ok {
+			return "ISBN tidak valid (cek kembali angka & check digit ISBN-10 / ISBN-13)"
+		}
+		book.ISBN = isbn
+	} else {
+		book.ISBN = ""
+	}
+
+	book.Publisher = strings.TrimSpace(book.Publisher)
+	book.Language = strings.TrimSpace(book.Language)
+
+	book.Format = strings.ToLower(strings.TrimSpace(book.Format))
+	switch book.Format {
+	case "", models.FormatPaperback, models.FormatHardcover, models.FormatEbook:
+	default:
+		return "Format harus paperback, hardcover, atau ebook"
+	}
+
+	if book.PublicationYear != nil && (*book.PublicationYear < 1000 || *book.PublicationYear > time.Now().Year()+1) {
+		return "Tahun terbit tidak valid"
+	}
+	if book.PageCount != nil && *book.PageCount <= 0 {
+		return "Jumlah halaman harus lebih dari 0"
+	}
+	if book.WeightGrams != nil && *book.WeightGrams < 0 {
+		return "Berat buku tidak boleh negatif"
+	}
+	return ""
+}
+
+// ISBN kosong disimpan sebagai NULL agar tidak bentrok dengan UNIQUE index
+func isbnValue(isbn string) interface{} {
+	if isbn == "" {
+		return nil
+	}
+	return isbn
+}
+
+// attachBookRelations mengisi kategori & penulis setiap buku (masing-masing satu query batch).
+func attachBookRelations(books []models.Book) error {
+	if err := attachBookCategories(books); err != nil {
+		return err
+	}
+	return attachBookAuthors(books)
+}
+
+// Pilihan ?sort= untuk katalog
+var bookSortOptions = map[string]string{
+	"newest":     "id DESC",
+	"price_asc":  "price ASC, id DESC",
+	"price_desc": "price DESC, id DESC",
+	"title":      "title ASC, id DESC",
+}
+
+// buildBookFilter menyusun WHERE clause katalog dari query param:
+// q (cari di judul/penulis/deskripsi, atau ISBN), category, author_id, min_price, max_price, in_stock,
+// archived (khusus admin: kosong = hanya buku aktif, "only" = hanya arsip, "all" = semua).
+// Jika ada param tidak valid, mengembalikan status code & pesan error.
+func buildBookFilter(r *http.Request) (string, []interface{}, int, string) {
+	q := r.URL.Query()
+	var conditions []string
+	var args []interface{}
+
+	switch archived := q.Get("archived"); archived {
+	case "":
+		conditions = append(conditions, "deleted_at IS NULL")
+	case "only", "all":
+		if !isAdmin(r) {
+			return "", nil, http.StatusForbidden, "Forbidden: hanya admin yang bisa melihat buku yang diarsipkan"
+		}
+		if archived == "only" {
+			conditions = append(conditions, "deleted_at IS NOT NULL")
+		}
+	default:
+		return "", nil, http.StatusBadRequest, "Nilai archived harus 'only' atau 'all'"
+	}
+
+	if search := strings.TrimSpace(q.Get("q")); search != "" {
+		pattern := likeContains(search)
+		if isbn, ok := utils.NormalizeISBN(search); ok {
+			// Pencarian berupa ISBN (boleh format 10 atau 13 digit)
+			conditions = append(conditions, "(isbn = ? OR title LIKE ? OR author LIKE ? OR description LIKE ?)")
+			args = append(args, isbn, pattern, pattern, pattern)
+		} else {
+			conditions = append(conditions, "(title LIKE ? OR author LIKE ? OR description LIKE ?)")
+			args = append(args, pattern, pattern, pattern)
+		}
+	}
+
+	// category bisa berupa slug atau nama; sub kategori ikut tercakup.
+	// Jika tidak ada kategori yang cocok, fallback ke kolom teks lama.
+	if category := strings.TrimSpace(q.Get("category")); category != "" {
+		ids, found, err := categoryFilterIDs(category)
+		if err != nil {
+			return "", nil, http.StatusInternalServerError, err.Error()
+		}
+		if found {
+			placeholders, idArgs := inClause(ids)
+			conditions = append(conditions, "id IN (SELECT book_id FROM book_categories WHERE category_id IN ("+placeholders+"))")
+			args = append(args, idArgs...)
+		} else {
+			conditions = append(conditions, "LOWER(category) = LOWER(?)")
+			args = append(args, category)
+		}
+	}
+
+	if authorID := q.Get("author_id"); authorID != "" {
+		id, err := strconv.Atoi(authorID)
+		if err != nil {
+			return "", nil, http.StatusBadRequest, "author_id harus berupa angka"
+		}
+		conditions = append(conditions, "id IN (SELECT book_id FROM book_authors WHERE author_id = ?)")
+		args = append(args, id)
+	}
+
+	if minPrice := q.Get("min_price"); minPrice != "" {
+		price, err := strconv.ParseFloat(minPrice, 64)
+		if err != nil {
+			return "", nil, http.StatusBadRequest, "min_price harus berupa angka"
+		}
+		conditions = append(conditions, "price >= ?")
+		args = append(args, price)
+	}
+
+	if maxPrice := q.Get("max_price"); maxPrice != "" {
+		price, err := strconv.ParseFloat(maxPrice, 64)
+		if err != nil {
+			return "", nil, http.StatusBadRequest, "max_price harus berupa angka"
+		}
+		conditions = append(conditions, "price <= ?")
+		args = append(args, price)
+	}
+
+	if inStock := q.Get("in_stock"); inStock == "true" || inStock == "1" {
+		conditions = append(conditions, "stock > 0")
+	}
+
+	if len(conditions) == 0 {
+		return "", args, 0, ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args, 0, ""
+}
+
+// Query param: q, category, author_id, min_price, max_price, in_stock, sort (newest|price_asc|price_desc|title),
+// page, limit, archived (khusus admin)
 func getBooks(w http.ResponseWriter, r *http.Request) {
-	rows, err := config.DB.Query("SELECT id, title, author, price, category, stock, image_url, description FROM books ORDER BY id DESC")
+	where, args, status, errMsg := buildBookFilter(r)
+	if errMsg != "" {
+		http.Error(w, errMsg, status)
+		return
+	}
+
+	orderBy, ok := bookSortOptions[r.URL.Query().Get("sort")]
+	if !ok {
+		orderBy = bookSortOptions["newest"]
+	}
+
+	// Response tetap array biasa (kompatibel dengan storefront lama).
+	// Pagination hanya aktif jika ?page= / ?limit= dikirim, info total ada di header X-Total-Count dkk.
+	query := "SELECT " + bookColumns + " FROM books " + where + " ORDER BY " + orderBy
+	paginated := r.URL.Query().Has("page") || r.URL.Query().Has("limit")
+	page, limit := parsePagination(r, 24, 100)
+
+	var total int
+	if paginated {
+		// Hitung total buku yang cocok (untuk header pagination)
+		if err := config.DB.QueryRow("SELECT COUNT(*) FROM books "+where, args...).Scan(&total); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, (page-1)*limit)
+	}
+
+	rows, err := config.DB.Query(query, args...)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	defer rows.Close()
 
-	var books []models.Book
+	books := []models.Book{}
 	for rows.Next() {
-		var book models.Book
-		// Scan urutannya harus sama dengan Query SELECT di atas
-		if err := rows.Scan(&book.ID, &book.Title, &book.Author, &book.Price, &book.Category, &book.Stock, &book.ImageURL, &book.Description); err != nil {
+		book, err := scanBook(rows)
+		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		books = append(books, book)
 	}
 
+	if err := attachBookRelations(books); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if paginated {
+		setPageHeaders(w, newPageMeta(page, limit, total))
+	} else {
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(books)))
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(books)
 }
 
-func getBook(w http.ResponseWriter, id int) {
-	var book models.Book
-	row := config.DB.QueryRow("SELECT id, title, author, price, category, stock, image_url, description FROM books WHERE id = ?", id)
+func getBook(w http.ResponseWriter, r *http.Request, id int) {
+	row := config.DB.QueryRow("SELECT "+bookColumns+" FROM books WHERE id = ?", id)
 
-	err := row.Scan(&book.ID, &book.Title, &book.Author, &book.Price, &book.Category, &book.Stock, &book.ImageURL, &book.Description)
+	book, err := scanBook(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			http.Error(w, "Book not found", http.StatusNotFound)
@@ -103,8 +327,20 @@ func getBook(w http.ResponseWriter, id int) {
 		return
 	}
 
+	// Buku yang diarsipkan hanya bisa dilihat admin
+	if book.DeletedAt != nil && !isAdmin(r) {
+		http.Error(w, "Book not found", http.StatusNotFound)
+		return
+	}
+
+	books := []models.Book{book}
+	if err := attachBookRelations(books); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(book)
+	json.NewEncoder(w).Encode(books[0])
 }
 
 func createBook(w http.ResponseWriter, r *http.Request) {
@@ -120,19 +356,74 @@ func createBook(w http.ResponseWriter, r *http.Request) {
 		book.ImageURL = "https://placehold.co/300x450?text=No+Image"
 	}
 
-	result, err := config.DB.Exec("INSERT INTO books (title, author, price, category, stock, image_url, description) VALUES (?, ?, ?, ?, ?, ?, ?)",
-		book.Title, book.Author, book.Price, book.Category, book.Stock, book.ImageURL, book.Description)
+	if msg := validateBookMetadata(&book); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
 
+	// Semua perubahan (kategori/penulis baru, buku, relasi) dalam satu transaksi,
+	// agar tidak ada data setengah jadi jika ISBN bentrok atau terjadi error
+	tx, err := config.DB.Begin()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	defer tx.Rollback()
+
+	// Kategori & penulis dari category_ids / author_ids, atau teks category / author untuk frontend lama
+	categoryIDs, status, msg := resolveBookCategories(tx, &book, 0)
+	if msg != "" {
+		http.Error(w, msg, status)
+		return
+	}
+	authorIDs, status, msg := resolveBookAuthors(tx, &book, 0)
+	if msg != "" {
+		http.Error(w, msg, status)
+		return
+	}
+
+	result, err := tx.Exec(`INSERT INTO books (title, author, price, category, stock, image_url, description,
+		isbn, publisher, publication_year, page_count, language, format, weight_grams) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		book.Title, book.Author, book.Price, book.Category, book.Stock, book.ImageURL, book.Description,
+		isbnValue(book.ISBN), book.Publisher, book.PublicationYear, book.PageCount, book.Language, book.Format, book.WeightGrams)
+
+	if err != nil {
+		if utils.IsDuplicateEntry(err) {
+			http.Error(w, "ISBN sudah dipakai buku lain", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	id, _ := result.LastInsertId()
 	book.ID = int(id)
 
+	if err := categoryRelation.set(tx, book.ID, categoryIDs); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := authorRelation.set(tx, book.ID, authorIDs); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	reindexBook(book.ID)
+
+	// Kembalikan data terbaru (kategori utama, nama penulis & relasinya)
+	book, err = scanBook(config.DB.QueryRow("SELECT "+bookColumns+" FROM books WHERE id = ?", book.ID))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	books := []models.Book{book}
+	attachBookRelations(books)
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(book)
+	json.NewEncoder(w).Encode(books[0])
 }
 
 func deleteImage(imageURL string) {
@@ -144,26 +435,56 @@ func deleteImage(imageURL string) {
 	// 2. Parsing URL untuk dapat nama file lokal
 	// URL: http://localhost:8080/uploads/1723123-buku.jpg
 	// Kita butuh: uploads/1723123-buku.jpg
+	localPath, ok := localUploadPath(imageURL)
+	if !ok {
+		return
+	}
 
+	// 3. Jangan hapus file yang masih dipakai snapshot pesanan lama (transaction_details.book_image)
+	inUse, err := imageInUse(imageURL)
+	if err != nil {
+		fmt.Println("Gagal cek pemakaian gambar, file tidak dihapus:", err)
+		return
+	}
+	if inUse {
+		fmt.Println("Gambar masih dipakai riwayat transaksi, file tidak dihapus:", localPath)
+		return
+	}
+
+	// 4. Hapus file
+	if err := os.Remove(localPath); err != nil {
+		fmt.Println("Gagal menghapus file lama:", err)
+		// Kita hanya print error, jangan stop proses update DB
+	} else {
+		fmt.Println("File lama berhasil dihapus:", localPath)
+	}
+}
+
+// localUploadPath mengubah URL gambar menjadi path file di folder uploads.
+// URL bisa berasal dari input admin (image_url/photo_url), jadi path yang keluar dari folder uploads
+// (misal "/uploads/../config/auth.go") ditolak. File upload selalu langsung di dalam uploads/.
+func localUploadPath(imageURL string) (string, bool) {
 	// Cari kata "/uploads/"
 	parts := strings.Split(imageURL, "/uploads/")
 	if len(parts) < 2 {
-		return // Format URL tidak dikenali
+		return "", false // Format URL tidak dikenali
 	}
 
 	filename := parts[1] // Ambil bagian setelah /uploads/
+	if filename == "" || filename == "." || filename == ".." || filename != filepath.Base(filename) || strings.Contains(filename, `\`) {
+		fmt.Println("Path gambar tidak valid, file tidak dihapus:", imageURL)
+		return "", false
+	}
 
 	// Gabungkan dengan folder lokal
-	localPath := filepath.Join("uploads", filename)
+	return filepath.Join("uploads", filename), true
+}
 
-	// 3. Hapus file
-	err := os.Remove(localPath)
-	if err != nil {
-		fmt.Println("Gagal menghapus file lama:", err)
-		// Kita hanya print error, jangan stop proses update DB
-	} else {
-		fmt.Println("File lama berhasil dihapus:", localPath)
-	}
+// imageInUse mengecek apakah URL gambar masih direferensikan detail transaksi
+func imageInUse(imageURL string) (bool, error) {
+	var count int
+	err := config.DB.QueryRow("SELECT COUNT(*) FROM transaction_details WHERE book_image = ?", imageURL).Scan(&count)
+	return count > 0, err
 }
 
 // --- UPDATE FUNGSI updateBook ---
@@ -183,49 +504,109 @@ func updateBook(w http.ResponseWriter, r *http.Request, id int) {
 		return
 	}
 
-	// 2. LOGIC HAPUS GAMBAR
-	// Jika URL yang dikirim beda dengan URL di database,
-	// dan URL di database tidak kosong
-	if book.ImageURL != "" && book.ImageURL != oldImageURL {
-		deleteImage(oldImageURL) // <--- HAPUS FILE LAMA
+	// Validasi data bibliografi, kategori & penulis sebelum mengubah apa pun
+	if msg := validateBookMetadata(&book); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	// Buku, kategori/penulis baru & relasinya diubah dalam satu transaksi
+	tx, err := config.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	categoryIDs, status, msg := resolveBookCategories(tx, &book, id)
+	if msg != "" {
+		http.Error(w, msg, status)
+		return
+	}
+	authorIDs, status, msg := resolveBookAuthors(tx, &book, id)
+	if msg != "" {
+		http.Error(w, msg, status)
+		return
 	}
 
-	// 3. UPDATE DATABASE (Seperti biasa)
-	_, err = config.DB.Exec("UPDATE books SET title=?, author=?, price=?, category=?, stock=?, description=?, image_url=? WHERE id=?",
-		book.Title, book.Author, book.Price, book.Category, book.Stock, book.Description, book.ImageURL, id)
+	// 2. UPDATE DATABASE (Seperti biasa)
+	_, err = tx.Exec(`UPDATE books SET title=?, author=?, price=?, category=?, stock=?, description=?, image_url=?,
+		isbn=?, publisher=?, publication_year=?, page_count=?, language=?, format=?, weight_grams=? WHERE id=?`,
+		book.Title, book.Author, book.Price, book.Category, book.Stock, book.Description, book.ImageURL,
+		isbnValue(book.ISBN), book.Publisher, book.PublicationYear, book.PageCount, book.Language, book.Format, book.WeightGrams, id)
 
 	if err != nil {
+		if utils.IsDuplicateEntry(err) {
+			http.Error(w, "ISBN sudah dipakai buku lain", http.StatusConflict)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	book.ID = id
+	if err := categoryRelation.set(tx, id, categoryIDs); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := authorRelation.set(tx, id, authorIDs); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// 3. LOGIC HAPUS GAMBAR (setelah commit berhasil, agar gambar lama tetap ada jika update gagal, mis. ISBN bentrok)
+	// Jika URL yang dikirim beda dengan URL di database,
+	// dan URL di database tidak kosong
+	if book.ImageURL != "" && book.ImageURL != oldImageURL {
+		deleteImage(oldImageURL) // <--- HAPUS FILE LAMA
+	}
+
+	reindexBook(id)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"message": "Book updated successfully"})
 }
 
+// deleteBook tidak benar-benar menghapus (soft delete): buku hanya diarsipkan
+// agar pesanan lama yang mereferensikan buku ini tetap utuh. Gambar juga tidak dihapus.
 func deleteBook(w http.ResponseWriter, id int) {
-	// 1. Ambil URL Gambar sebelum dihapus
-	var oldImageURL string
-	row := config.DB.QueryRow("SELECT image_url FROM books WHERE id = ?", id)
-	err := row.Scan(&oldImageURL)
-
-	// Jika buku tidak ada, return error
+	result, err := config.DB.Exec("UPDATE books SET deleted_at = NOW() WHERE id = ? AND deleted_at IS NULL", id)
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Jika buku tidak ada (atau sudah diarsipkan), return error
+	if affected, _ := result.RowsAffected(); affected == 0 {
 		http.Error(w, "Book not found", http.StatusNotFound)
 		return
 	}
 
-	// 2. Hapus Data dari DB
-	_, err = config.DB.Exec("DELETE FROM books WHERE id=?", id)
+	searchIndex.Remove(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Book archived successfully"})
+}
+
+// restoreBook mengembalikan buku yang diarsipkan (gambar tetap dipakai)
+func restoreBook(w http.ResponseWriter, id int) {
+	result, err := config.DB.Exec("UPDATE books SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// 3. Hapus File Fisik
-	deleteImage(oldImageURL)
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		http.Error(w, "Archived book not found", http.StatusNotFound)
+		return
+	}
+
+	reindexBook(id)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "Book deleted successfully"})
+	json.NewEncoder(w).Encode(map[string]string{"message": "Book restored successfully"})
 }
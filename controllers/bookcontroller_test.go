@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+)
+
+// Gambar lama yang masih dipakai snapshot pesanan tidak boleh ikut terhapus saat buku diedit.
+func TestDeleteImageKeepsFileReferencedByTransactions(t *testing.T) {
+	mock := mockDB(t)
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("uploads", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"dipakai.jpg", "bebas.jpg"} {
+		if err := os.WriteFile(filepath.Join("uploads", name), []byte("img"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	used := "http://localhost:8080/uploads/dipakai.jpg"
+	mock.ExpectQuery("FROM transaction_details WHERE book_image = \\?").WithArgs(used).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	deleteImage(used)
+	if _, err := os.Stat(filepath.Join("uploads", "dipakai.jpg")); err != nil {
+		t.Errorf("referenced image was deleted: %v", err)
+	}
+
+	unused := "http://localhost:8080/uploads/bebas.jpg"
+	mock.ExpectQuery("FROM transaction_details WHERE book_image = \\?").WithArgs(unused).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	deleteImage(unused)
+	if _, err := os.Stat(filepath.Join("uploads", "bebas.jpg")); !os.IsNotExist(err) {
+		t.Errorf("unreferenced image still exists (err = %v)", err)
+	}
+}
+
+// Tanpa ?page= / ?limit= katalog tetap array biasa berisi semua buku (storefront lama bergantung pada ini).
+func TestGetBooksKeepsBareArray(t *testing.T) {
+	mock := mockDB(t)
+	mock.ExpectQuery("SELECT .+ FROM books WHERE .+ ORDER BY id DESC$").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	rec := httptest.NewRecorder()
+	getBooks(rec, httptest.NewRequest("GET", "/api/books", nil))
+
+	if body := strings.TrimSpace(rec.Body.String()); body != "[]" {
+		t.Errorf("body = %s, want []", body)
+	}
+	if got := rec.Header().Get("X-Total-Count"); got != "0" {
+		t.Errorf("X-Total-Count = %q, want 0", got)
+	}
+}
+
+func TestGetBooksPaginationHeaders(t *testing.T) {
+	mock := mockDB(t)
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM books").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+	mock.ExpectQuery("FROM books .+ LIMIT \\? OFFSET \\?").WithArgs(10, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	rec := httptest.NewRecorder()
+	getBooks(rec, httptest.NewRequest("GET", "/api/books?page=2&limit=10", nil))
+
+	want := map[string]string{"X-Total-Count": "25", "X-Page": "2", "X-Limit": "10", "X-Total-Pages": "3"}
+	for header, value := range want {
+		if got := rec.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+	if body := strings.TrimSpace(rec.Body.String()); body != "[]" {
+		t.Errorf("body = %s, want []", body)
+	}
+}
+
+// photo_url/image_url bisa diisi admin bebas: path di luar uploads/ tidak boleh dihapus.
+func TestLocalUploadPath(t *testing.T) {
+	tests := []struct {
+		url, path string
+		ok        bool
+	}{
+		{"http://localhost:8080/uploads/1723123-buku.jpg", filepath.Join("uploads", "1723123-buku.jpg"), true},
+		{"/uploads/sampul..final.png", filepath.Join("uploads", "sampul..final.png"), true},
+		{"https://placehold.co/400x600", "", false},
+		{"http://localhost:8080/uploads/../config/auth.go", "", false},
+		{"http://localhost:8080/uploads/sub/../../main.go", "", false},
+		{"/uploads/..", "", false},
+		{"/uploads/.", "", false},
+		{"/uploads/", "", false},
+		{`/uploads/..\main.go`, "", false},
+	}
+
+	for _, tc := range tests {
+		path, ok := localUploadPath(tc.url)
+		if path != tc.path || ok != tc.ok {
+			t.Errorf("localUploadPath(%q) = %q, %v; want %q, %v", tc.url, path, ok, tc.path, tc.ok)
+		}
+	}
+}
+
+// ISBN bentrok saat tambah buku: penulis baru dari teks "author" tidak boleh tertinggal (yatim).
+func TestCreateBookRollsBackOnDuplicateISBN(t *testing.T) {
+	mock := mockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM authors WHERE LOWER\\(name\\)").WithArgs("Penulis Baru").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT INTO authors").WithArgs("Penulis Baru").WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectExec("INSERT INTO books").WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+	mock.ExpectRollback()
+
+	body := `{"title": "Bumi", "author": "Penulis Baru", "price": 90000, "isbn": "978-602-03-3295-6"}`
+	rec := httptest.NewRecorder()
+	createBook(rec, httptest.NewRequest("POST", "/api/books", strings.NewReader(body)))
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d (body: %s)", rec.Code, http.StatusConflict, rec.Body.String())
+	}
+}
+
+// ISBN bentrok saat edit buku: relasi lama dan gambar lama tetap utuh.
+func TestUpdateBookRollsBackOnDuplicateISBN(t *testing.T) {
+	mock := mockDB(t)
+	mock.ExpectQuery("SELECT image_url FROM books").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"image_url"}).AddRow("http://localhost:8080/uploads/lama.jpg"))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM categories WHERE id IN").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM authors WHERE id IN").WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec("UPDATE books SET").WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+	mock.ExpectRollback()
+	// Tidak ada DELETE relasi maupun cek/hapus gambar setelah ini (sqlmock menolak query lain)
+
+	body := `{"title": "Bumi", "price": 90000, "isbn": "978-602-03-3295-6", "image_url": "http://localhost:8080/uploads/baru.jpg",
+		"category_ids": [3], "author_ids": [4]}`
+	rec := httptest.NewRecorder()
+	updateBook(rec, httptest.NewRequest("PUT", "/api/books/5", strings.NewReader(body)), 5)
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d (body: %s)", rec.Code, http.StatusConflict, rec.Body.String())
+	}
+}
+
+// Form lama mengirim balik buku tanpa category_ids (hanya "category" = kategori utama):
+// mengubah harga saja tidak boleh membuang kategori kedua buku.
+func TestUpdateBookWithoutCategoryIDsKeepsAllCategories(t *testing.T) {
+	mock := mockDB(t)
+	image := "http://localhost:8080/uploads/bumi.jpg"
+	mock.ExpectQuery("SELECT image_url FROM books").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"image_url"}).AddRow(image))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT category FROM books WHERE id = \\?").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"category"}).AddRow("Fiksi"))
+	mock.ExpectQuery("SELECT category_id FROM book_categories WHERE book_id = \\?").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(3).AddRow(8))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM authors WHERE id IN").WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec("UPDATE books SET title").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM book_categories WHERE book_id = \\?").WithArgs(5).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO book_categories").WithArgs(5, 3, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO book_categories").WithArgs(5, 8, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE books SET category").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM book_authors WHERE book_id = \\?").WithArgs(5).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO book_authors").WithArgs(5, 4, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE books SET author").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("FROM books WHERE id = \\?").WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"id"})) // reindexBook
+
+	body := `{"title": "Bumi", "price": 95000, "category": "fiksi ", "image_url": "` + image + `", "author_ids": [4]}`
+	rec := httptest.NewRecorder()
+	updateBook(rec, httptest.NewRequest("PUT", "/api/books/5", strings.NewReader(body)), 5)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"be/config"
+	"be/models"
+	"database/sql"
+	"log"
+	"net/http"
+)
+
+// querier dipenuhi *sql.DB maupun *sql.Tx, agar fungsi relasi bisa ikut transaksi pemanggil.
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// bookRelation menjelaskan relasi many-to-many buku dengan kategori / penulis.
+// Keduanya disimpan dengan pola yang sama: tabel relasi (book_id, <column>, position),
+// dan isinya disalin ke kolom teks lama di tabel books lewat sync.
+type bookRelation struct {
+	table    string                            // Tabel relasi, misal "book_categories"
+	column   string                            // Kolom id lawan relasi, misal "category_id"
+	target   string                            // Tabel lawan relasi, misal "categories"
+	notFound string                            // Pesan 400 jika ada id yang tidak ditemukan
+	sync     func(q querier, bookID int) error // Sinkron kolom teks lama (books.category / books.author)
+}
+
+var (
+	categoryRelation = bookRelation{
+		table:    "book_categories",
+		column:   "category_id",
+		target:   "categories",
+		notFound: "Kategori tidak ditemukan",
+		sync:     syncBookPrimaryCategory,
+	}
+	authorRelation = bookRelation{
+		table:    "book_authors",
+		column:   "author_id",
+		target:   "authors",
+		notFound: "Penulis tidak ditemukan",
+		sync:     syncBookAuthorNames,
+	}
+)
+
+// bookIDs mengambil semua buku yang terhubung ke satu kategori / penulis.
+func (rel bookRelation) bookIDs(targetID int) ([]int, error) {
+	rows, err := config.DB.Query("SELECT book_id FROM "+rel.table+" WHERE "+rel.column+" = ?", targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// currentIDs mengambil relasi buku yang tersimpan sekarang (urut position).
+func (rel bookRelation) currentIDs(q querier, bookID int) ([]int, error) {
+	rows, err := q.Query("SELECT "+rel.column+" FROM "+rel.table+" WHERE book_id = ? ORDER BY position ASC, "+rel.column+" ASC", bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// syncBooks menyinkronkan ulang kolom teks lama dan index pencarian buku-buku tersebut.
+func (rel bookRelation) syncBooks(bookIDs []int) {
+	for _, bookID := range bookIDs {
+		if err := rel.sync(config.DB, bookID); err != nil {
+			log.Println("Gagal sinkron buku dari "+rel.table+":", err)
+		}
+		reindexBook(bookID)
+	}
+}
+
+// refreshBooks menyinkronkan ulang semua buku sebuah kategori / penulis (setelah datanya diubah).
+func (rel bookRelation) refreshBooks(targetID int) {
+	bookIDs, err := rel.bookIDs(targetID)
+	if err != nil {
+		log.Println("Gagal ambil buku dari "+rel.table+":", err)
+		return
+	}
+	rel.syncBooks(bookIDs)
+}
+
+// validateIDs membuang id dobel (urutan dipertahankan) dan memastikan semuanya ada.
+func (rel bookRelation) validateIDs(q querier, ids []int) ([]int, int, string) {
+	ids = uniqueInts(ids)
+	if len(ids) == 0 {
+		return ids, 0, ""
+	}
+
+	placeholders, args := inClause(ids)
+	var count int
+	err := q.QueryRow("SELECT COUNT(*) FROM "+rel.target+" WHERE id IN ("+placeholders+")", args...).Scan(&count)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err.Error()
+	}
+	if count != len(ids) {
+		return nil, http.StatusBadRequest, rel.notFound
+	}
+	return ids, 0, ""
+}
+
+// set mengganti seluruh relasi buku (urutan = position, yang pertama paling utama).
+// Dijalankan di dalam transaksi pemanggil (createBook / updateBook).
+func (rel bookRelation) set(tx querier, bookID int, ids []int) error {
+	if _, err := tx.Exec("DELETE FROM "+rel.table+" WHERE book_id = ?", bookID); err != nil {
+		return err
+	}
+	for position, id := range ids {
+		if _, err := tx.Exec("INSERT INTO "+rel.table+" (book_id, "+rel.column+", position) VALUES (?, ?, ?)", bookID, id, position); err != nil {
+			return err
+		}
+	}
+	return rel.sync(tx, bookID)
+}
+
+// bookIDList mengambil id dari daftar buku (untuk query batch relasi).
+func bookIDList(books []models.Book) []int {
+	ids := make([]int, len(books))
+	for i, b := range books {
+		ids[i] = b.ID
+	}
+	return ids
+}
+
+func uniqueInts(values []int) []int {
+	seen := map[int]bool{}
+	result := []int{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,417 @@
+package controllers
+
+import (
+	"be/config"
+	"be/models"
+	"be/utils"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// 1. LIST & CREATE (URL: /api/categories, ?tree=true untuk bentuk pohon)
+func CategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		getCategories(w, r)
+	case "POST":
+		createCategory(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// 2. GET DETAIL, UPDATE, DELETE (URL: /api/categories/{id})
+func CategoryDetailHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	id, action, ok := parseIDPath(r.URL.Path, "/api/categories/")
+	if !ok || action != "" {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		getCategory(w, id)
+	case "PUT":
+		updateCategory(w, r, id)
+	case "DELETE":
+		deleteCategory(w, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// loadCategories mengambil semua kategori beserta jumlah buku aktifnya.
+// Tabel kategori kecil, jadi hierarki cukup diolah di Go.
+func loadCategories() ([]models.Category, error) {
+	rows, err := config.DB.Query(`
+		SELECT c.id, c.name, c.slug, c.parent_id, COUNT(b.id)
+		FROM categories c
+		LEFT JOIN book_categories bc ON bc.category_id = c.id
+		LEFT JOIN books b ON b.id = bc.book_id AND b.deleted_at IS NULL
+		GROUP BY c.id, c.name, c.slug, c.parent_id
+		ORDER BY c.name ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.Category{}
+	for rows.Next() {
+		var c models.Category
+		var parentID sql.NullInt64
+		if err := rows.Scan(&c.ID, &c.Name, &c.Slug, &parentID, &c.BookCount); err != nil {
+			return nil, err
+		}
+		if parentID.Valid {
+			pid := int(parentID.Int64)
+			c.ParentID = &pid
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// buildCategoryTree menyusun list datar menjadi pohon (kategori utama -> sub kategori).
+func buildCategoryTree(categories []models.Category, parentID *int) []models.Category {
+	tree := []models.Category{}
+	for _, c := range categories {
+		if (parentID == nil && c.ParentID == nil) || (parentID != nil && c.ParentID != nil && *c.ParentID == *parentID) {
+			id := c.ID
+			c.Children = buildCategoryTree(categories, &id)
+			tree = append(tree, c)
+		}
+	}
+	return tree
+}
+
+// categoryDescendants mengembalikan ID kategori beserta semua sub kategorinya.
+func categoryDescendants(categories []models.Category, id int) []int {
+	ids := []int{id}
+	for _, c := range categories {
+		if c.ParentID != nil && *c.ParentID == id {
+			ids = append(ids, categoryDescendants(categories, c.ID)...)
+		}
+	}
+	return ids
+}
+
+func getCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := loadCategories()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Query().Get("tree") == "true" {
+		json.NewEncoder(w).Encode(buildCategoryTree(categories, nil))
+		return
+	}
+	json.NewEncoder(w).Encode(categories)
+}
+
+func getCategory(w http.ResponseWriter, id int) {
+	categories, err := loadCategories()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, c := range categories {
+		if c.ID == id {
+			c.Children = buildCategoryTree(categories, &c.ID)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(c)
+			return
+		}
+	}
+	http.Error(w, "Category not found", http.StatusNotFound)
+}
+
+// validateCategory merapikan input dan mengecek parent (harus ada & tidak membuat siklus).
+func validateCategory(c *models.Category, id int) (int, string) {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return http.StatusBadRequest, "Nama kategori wajib diisi"
+	}
+
+	if strings.TrimSpace(c.Slug) == "" {
+		c.Slug = c.Name
+	}
+	c.Slug = models.Slugify(c.Slug)
+	if c.Slug == "" {
+		return http.StatusBadRequest, "Slug tidak valid"
+	}
+
+	if c.ParentID == nil {
+		return 0, ""
+	}
+
+	categories, err := loadCategories()
+	if err != nil {
+		return http.StatusInternalServerError, err.Error()
+	}
+
+	found := false
+	for _, existing := range categories {
+		if existing.ID == *c.ParentID {
+			found = true
+		}
+	}
+	if !found {
+		return http.StatusBadRequest, "Parent kategori tidak ditemukan"
+	}
+
+	// Parent tidak boleh kategori itu sendiri atau salah satu sub kategorinya
+	if id > 0 {
+		for _, descendant := range categoryDescendants(categories, id) {
+			if descendant == *c.ParentID {
+				return http.StatusBadRequest, "Parent kategori tidak boleh sub kategori sendiri"
+			}
+		}
+	}
+	return 0, ""
+}
+
+func createCategory(w http.ResponseWriter, r *http.Request) {
+	var c models.Category
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if status, msg := validateCategory(&c, 0); msg != "" {
+		http.Error(w, msg, status)
+		return
+	}
+
+	result, err := config.DB.Exec("INSERT INTO categories (name, slug, parent_id) VALUES (?, ?, ?)", c.Name, c.Slug, c.ParentID)
+	if err != nil {
+		if utils.IsDuplicateEntry(err) {
+			http.Error(w, "Slug kategori sudah dipakai", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	c.ID = int(id)
+	c.Children = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
+
+func updateCategory(w http.ResponseWriter, r *http.Request, id int) {
+	var c models.Category
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if status, msg := validateCategory(&c, id); msg != "" {
+		http.Error(w, msg, status)
+		return
+	}
+
+	result, err := config.DB.Exec("UPDATE categories SET name=?, slug=?, parent_id=? WHERE id=?", c.Name, c.Slug, c.ParentID, id)
+	if err != nil {
+		if utils.IsDuplicateEntry(err) {
+			http.Error(w, "Slug kategori sudah dipakai", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// RowsAffected 0 bisa berarti tidak ada perubahan, jadi cek keberadaannya terpisah
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		var exists int
+		config.DB.QueryRow("SELECT COUNT(*) FROM categories WHERE id = ?", id).Scan(&exists)
+		if exists == 0 {
+			http.Error(w, "Category not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	// Nama kategori berubah -> perbarui kolom books.category & index pencarian
+	categoryRelation.refreshBooks(id)
+
+	c.ID = id
+	c.Children = nil
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+func deleteCategory(w http.ResponseWriter, id int) {
+	var children int
+	config.DB.QueryRow("SELECT COUNT(*) FROM categories WHERE parent_id = ?", id).Scan(&children)
+	if children > 0 {
+		http.Error(w, "Kategori masih punya sub kategori, pindahkan atau hapus dulu", http.StatusConflict)
+		return
+	}
+
+	bookIDs, err := categoryRelation.bookIDs(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := config.DB.Exec("DELETE FROM categories WHERE id=?", id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+
+	if _, err := config.DB.Exec("DELETE FROM book_categories WHERE category_id=?", id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	categoryRelation.syncBooks(bookIDs)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Category deleted successfully"})
+}
+
+// --- RELASI BUKU <-> KATEGORI ---
+
+// syncBookPrimaryCategory mengisi kolom lama books.category dengan nama kategori pertama buku,
+// agar frontend lama yang masih membaca field "category" tetap benar.
+func syncBookPrimaryCategory(q querier, bookID int) error {
+	_, err := q.Exec(`
+		UPDATE books SET category = IFNULL((
+			SELECT c.name FROM book_categories bc
+			JOIN categories c ON c.id = bc.category_id
+			WHERE bc.book_id = ?
+			ORDER BY bc.position ASC, c.id ASC
+			LIMIT 1
+		), '')
+		WHERE id = ?`, bookID, bookID)
+	return err
+}
+
+// resolveBookCategories menentukan kategori buku dari input. Jika book.CategoryIDs dikirim,
+// itu yang dipakai (harus ada semua). Jika tidak, teks book.Category dicocokkan dengan slug
+// kategori (dibuat jika belum ada), agar frontend lama yang hanya mengirim "category" tetap berfungsi.
+// bookID diisi saat edit (0 saat buku baru).
+func resolveBookCategories(q querier, book *models.Book, bookID int) ([]int, int, string) {
+	if book.CategoryIDs == nil {
+		name := strings.TrimSpace(book.Category)
+		slug := models.Slugify(name)
+		if slug == "" {
+			return []int{}, 0, ""
+		}
+
+		// Form lama hanya mengirim balik kategori utama: jika tidak diganti, semua kategori buku dipertahankan
+		if bookID != 0 {
+			var current string
+			if err := q.QueryRow("SELECT category FROM books WHERE id = ?", bookID).Scan(&current); err != nil {
+				return nil, http.StatusInternalServerError, err.Error()
+			}
+			if strings.EqualFold(name, strings.TrimSpace(current)) {
+				ids, err := categoryRelation.currentIDs(q, bookID)
+				if err != nil {
+					return nil, http.StatusInternalServerError, err.Error()
+				}
+				if len(ids) > 0 {
+					return ids, 0, ""
+				}
+			}
+		}
+
+		// Pakai kategori yang sudah ada (cocok nama atau slug) supaya tidak dobel saat slug sudah diubah admin
+		var id int
+		err := q.QueryRow(`SELECT id FROM categories WHERE LOWER(name) = LOWER(?) OR slug = ?
+			ORDER BY LOWER(name) = LOWER(?) DESC, id LIMIT 1`, name, slug, name).Scan(&id)
+		if err == sql.ErrNoRows {
+			result, err := q.Exec("INSERT INTO categories (name, slug) VALUES (?, ?)", name, slug)
+			if err != nil {
+				return nil, http.StatusInternalServerError, err.Error()
+			}
+			newID, _ := result.LastInsertId()
+			id = int(newID)
+		} else if err != nil {
+			return nil, http.StatusInternalServerError, err.Error()
+		}
+		return []int{id}, 0, ""
+	}
+
+	return categoryRelation.validateIDs(q, book.CategoryIDs)
+}
+
+// attachBookCategories mengisi Categories (dan Category utama) setiap buku dengan satu query batch.
+func attachBookCategories(books []models.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	placeholders, args := inClause(bookIDList(books))
+	rows, err := config.DB.Query(`
+		SELECT bc.book_id, c.id, c.name, c.slug, c.parent_id
+		FROM book_categories bc
+		JOIN categories c ON c.id = bc.category_id
+		WHERE bc.book_id IN (`+placeholders+`)
+		ORDER BY bc.position ASC, c.id ASC`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	byBook := map[int][]models.Category{}
+	for rows.Next() {
+		var bookID int
+		var c models.Category
+		var parentID sql.NullInt64
+		if err := rows.Scan(&bookID, &c.ID, &c.Name, &c.Slug, &parentID); err != nil {
+			return err
+		}
+		if parentID.Valid {
+			pid := int(parentID.Int64)
+			c.ParentID = &pid
+		}
+		byBook[bookID] = append(byBook[bookID], c)
+	}
+
+	for i := range books {
+		books[i].Categories = byBook[books[i].ID]
+		if books[i].Categories == nil {
+			books[i].Categories = []models.Category{}
+		}
+	}
+	return rows.Err()
+}
+
+// categoryFilterIDs mencari kategori dari slug / nama (?category=) beserta semua sub kategorinya.
+func categoryFilterIDs(param string) ([]int, bool, error) {
+	categories, err := loadCategories()
+	if err != nil {
+		return nil, false, err
+	}
+
+	slug := models.Slugify(param)
+	for _, c := range categories {
+		if c.Slug == slug {
+			return categoryDescendants(categories, c.ID), true, nil
+		}
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"be/config"
+	"be/models"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Kategori yang slug-nya sudah diubah admin tidak boleh digandakan oleh migrasi
+// (yang jalan setiap start) maupun oleh input kategori teks dari form lama.
+func TestLegacyCategoryReusesRenamedSlug(t *testing.T) {
+	openTestDB(t)
+
+	name := fmt.Sprintf("Fiksi Uji %d", time.Now().UnixNano())
+	insertBook := func() int {
+		result, err := config.DB.Exec("INSERT INTO books (title, author, price, category, stock, image_url, description) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			"Bumi", "Tere Liye", 90000, name, 1, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, _ := result.LastInsertId()
+		return int(id)
+	}
+
+	first := insertBook()
+	config.Migrate()
+
+	var categoryID int
+	if err := config.DB.QueryRow("SELECT category_id FROM book_categories WHERE book_id = ?", first).Scan(&categoryID); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		config.DB.Exec("DELETE FROM book_categories WHERE category_id = ?", categoryID)
+		config.DB.Exec("DELETE FROM books WHERE category = ?", name)
+		config.DB.Exec("DELETE FROM categories WHERE LOWER(name) = LOWER(?)", name)
+	})
+
+	// Admin mengganti slug, lalu ada buku lama lain dengan kategori teks yang sama
+	if _, err := config.DB.Exec("UPDATE categories SET slug = ? WHERE id = ?", fmt.Sprintf("slug-admin-%d", categoryID), categoryID); err != nil {
+		t.Fatal(err)
+	}
+	second := insertBook()
+	config.Migrate()
+
+	var count int
+	if err := config.DB.QueryRow("SELECT COUNT(*) FROM categories WHERE LOWER(name) = LOWER(?)", name).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("got %d categories named %q, want 1", count, name)
+	}
+
+	for _, bookID := range []int{first, second} {
+		var ids []int
+		rows, err := config.DB.Query("SELECT category_id FROM book_categories WHERE book_id = ?", bookID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for rows.Next() {
+			var id int
+			rows.Scan(&id)
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if len(ids) != 1 || ids[0] != categoryID {
+			t.Errorf("book %d categories = %v, want [%d]", bookID, ids, categoryID)
+		}
+	}
+
+	// Form lama mengirim nama kategori sebagai teks
+	ids, status, errMsg := resolveBookCategories(config.DB, &models.Book{Category: " " + name + " "}, 0)
+	if errMsg != "" {
+		t.Fatalf("resolveBookCategories: %d %s", status, errMsg)
+	}
+	if len(ids) != 1 || ids[0] != categoryID {
+		t.Errorf("resolveBookCategories = %v, want [%d]", ids, categoryID)
+	}
+}
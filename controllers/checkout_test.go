@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"be/config"
+	"be/models"
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Banyak checkout paralel untuk buku dengan stok terbatas: stok tidak boleh minus,
+// hanya sebanyak stok yang berhasil, sisanya 409 dengan book_ids buku yang kurang.
+func TestCheckoutConcurrentStockReservation(t *testing.T) {
+	openTestDB(t)
+
+	const stock, buyers = 5, 20
+
+	result, err := config.DB.Exec("INSERT INTO books (title, author, price, category, stock, image_url, description) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		"Laskar Pelangi", "Andrea Hirata", 85000, "", stock, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := result.LastInsertId()
+	bookID := int(id)
+	t.Cleanup(func() {
+		config.DB.Exec("DELETE FROM transaction_details WHERE book_id = ?", bookID)
+		config.DB.Exec("DELETE FROM books WHERE id = ?", bookID)
+	})
+
+	type response struct {
+		status  int
+		bookIDs []int
+	}
+	responses := make(chan response, buyers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			body, _ := json.Marshal(map[string]interface{}{
+				"customer_name":    fmt.Sprintf("Pembeli %d", i),
+				"customer_email":   fmt.Sprintf("pembeli%d@example.com", i),
+				"customer_phone":   "08123456789",
+				"customer_address": "Jl. Merdeka 1",
+				"payment_method":   "transfer",
+				"details":          []map[string]int{{"book_id": bookID, "quantity": 1}},
+			})
+			rec := httptest.NewRecorder()
+			CheckoutHandler(rec, httptest.NewRequest("POST", "/api/checkout", bytes.NewReader(body)))
+
+			var payload struct {
+				BookIDs []int `json:"book_ids"`
+			}
+			if rec.Code == http.StatusConflict {
+				json.Unmarshal(rec.Body.Bytes(), &payload)
+			}
+			responses <- response{status: rec.Code, bookIDs: payload.BookIDs}
+		}(i)
+	}
+	wg.Wait()
+	close(responses)
+
+	created, conflicts := 0, 0
+	for resp := range responses {
+		switch resp.status {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+			conflicts++
+			if len(resp.bookIDs) != 1 || resp.bookIDs[0] != bookID {
+				t.Errorf("409 book_ids = %v, want [%d]", resp.bookIDs, bookID)
+			}
+		default:
+			t.Errorf("unexpected status %d", resp.status)
+		}
+	}
+
+	if created != stock || conflicts != buyers-stock {
+		t.Errorf("created = %d, conflicts = %d, want %d and %d", created, conflicts, stock, buyers-stock)
+	}
+
+	var remaining, sold int
+	config.DB.QueryRow("SELECT stock FROM books WHERE id = ?", bookID).Scan(&remaining)
+	config.DB.QueryRow("SELECT COALESCE(SUM(quantity), 0) FROM transaction_details WHERE book_id = ?", bookID).Scan(&sold)
+	if remaining != 0 || sold != stock {
+		t.Errorf("stock = %d, sold = %d, want 0 and %d", remaining, sold, stock)
+	}
+}
+
+// postCheckout mengirim checkout tamu dengan item keranjang & total dari browser.
+func postCheckout(details []map[string]interface{}, total float64) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]interface{}{
+		"customer_name":    "Budi",
+		"customer_email":   "budi@example.com",
+		"customer_phone":   "08123456789",
+		"customer_address": "Jl. Merdeka 1",
+		"payment_method":   "transfer",
+		"total_amount":     total,
+		"details":          details,
+	})
+	rec := httptest.NewRecorder()
+	CheckoutHandler(rec, httptest.NewRequest("POST", "/api/checkout", bytes.NewReader(body)))
+	return rec
+}
+
+// expectBookPrices menyiapkan query harga buku (FOR UPDATE) untuk buku yang masih aktif.
+func expectBookPrices(mock sqlmock.Sqlmock, ids []int, books ...[]driver.Value) {
+	args := make([]driver.Value, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows := sqlmock.NewRows([]string{"id", "price", "title", "author", "image_url"})
+	for _, b := range books {
+		rows.AddRow(b...)
+	}
+	mock.ExpectQuery("SELECT id, price, title, author, image_url FROM books WHERE id IN .+ AND deleted_at IS NULL FOR UPDATE").
+		WithArgs(args...).WillReturnRows(rows)
+}
+
+var (
+	laskarPelangi = []driver.Value{1, 85000.0, "Laskar Pelangi", "Andrea Hirata", "/uploads/laskar.jpg"}
+	bumi          = []driver.Value{2, 50000.0, "Bumi", "Tere Liye", "/uploads/bumi.jpg"}
+)
+
+// Harga & total dihitung dari books.price (browser hanya mengirim book_id & jumlah),
+// dan baris keranjang dengan book_id sama digabung.
+func TestCheckoutUsesServerPricesAndMergesDuplicateLines(t *testing.T) {
+	mock := mockDB(t)
+	mock.ExpectBegin()
+	expectBookPrices(mock, []int{1, 2}, laskarPelangi, bumi)
+	mock.ExpectExec("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), nil, "Budi", "budi@example.com", "08123456789", "Jl. Merdeka 1", "transfer",
+			305000.0, models.StatusPendingPayment, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(10, 1))
+	mock.ExpectExec("INSERT INTO transaction_status_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE books SET stock = stock - \\?").WithArgs(3, 1, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE books SET stock = stock - \\?").WithArgs(1, 2, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO transaction_details").
+		WithArgs(10, 1, 3, 85000.0, "Laskar Pelangi", "Andrea Hirata", "/uploads/laskar.jpg").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO transaction_details").
+		WithArgs(10, 2, 1, 50000.0, "Bumi", "Tere Liye", "/uploads/bumi.jpg").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	rec := postCheckout([]map[string]interface{}{
+		{"book_id": 1, "quantity": 1},
+		{"book_id": 2, "quantity": 1},
+		{"book_id": 1, "quantity": 2},
+	}, 0)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d (body: %s)", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	var resp struct {
+		TotalAmount float64 `json:"total_amount"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.TotalAmount != 305000 {
+		t.Errorf("total_amount = %v, want 305000", resp.TotalAmount)
+	}
+}
+
+// Harga per item atau total dari browser yang tidak sama dengan harga server -> 409 beserta harga server,
+// tanpa menyimpan pesanan ("beli buku apa pun seharga 1 rupiah").
+func TestCheckoutRejectsClientPriceMismatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		details []map[string]interface{}
+		total   float64
+	}{
+		{"item price", []map[string]interface{}{{"book_id": 1, "quantity": 2, "price": 1}}, 0},
+		{"total", []map[string]interface{}{{"book_id": 1, "quantity": 2}}, 1},
+		{"item price and total", []map[string]interface{}{{"book_id": 1, "quantity": 2, "price": 1}}, 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := mockDB(t)
+			mock.ExpectBegin()
+			expectBookPrices(mock, []int{1}, laskarPelangi)
+			mock.ExpectRollback()
+
+			rec := postCheckout(tc.details, tc.total)
+			if rec.Code != http.StatusConflict {
+				t.Fatalf("status = %d, want %d (body: %s)", rec.Code, http.StatusConflict, rec.Body.String())
+			}
+
+			var resp struct {
+				Items       []models.TransactionDetail `json:"items"`
+				TotalAmount float64                    `json:"total_amount"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.TotalAmount != 170000 || len(resp.Items) != 1 || resp.Items[0].Price != 85000 {
+				t.Errorf("got total %v items %+v, want server price 85000 and total 170000", resp.TotalAmount, resp.Items)
+			}
+		})
+	}
+}
+
+// Buku yang tidak ada atau sudah diarsipkan (tidak ikut hasil query) -> 400 dengan book_ids.
+func TestCheckoutRejectsUnknownOrArchivedBook(t *testing.T) {
+	mock := mockDB(t)
+	mock.ExpectBegin()
+	expectBookPrices(mock, []int{1, 7, 9}, laskarPelangi) // 7 diarsipkan, 9 tidak ada
+	mock.ExpectRollback()
+
+	rec := postCheckout([]map[string]interface{}{
+		{"book_id": 1, "quantity": 1},
+		{"book_id": 7, "quantity": 1},
+		{"book_id": 9, "quantity": 1},
+	}, 0)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d (body: %s)", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+	var resp struct {
+		BookIDs []int `json:"book_ids"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.BookIDs) != 2 || resp.BookIDs[0] != 7 || resp.BookIDs[1] != 9 {
+		t.Errorf("book_ids = %v, want [7 9]", resp.BookIDs)
+	}
+}
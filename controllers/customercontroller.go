@@ -0,0 +1,427 @@
+package controllers
+
+import (
+	"be/config"
+	"be/models"
+	"be/utils"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+)
+
+// 1. REGISTRASI CUSTOMER (URL: /api/register)
+func RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.Phone = strings.TrimSpace(req.Phone)
+
+	if req.Name == "" {
+		http.Error(w, "Nama wajib diisi", http.StatusBadRequest)
+		return
+	}
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		http.Error(w, "Format email tidak valid", http.StatusBadRequest)
+		return
+	}
+	if !validPassword(req.Password) {
+		http.Error(w, "Password minimal 8 karakter", http.StatusBadRequest)
+		return
+	}
+
+	// Email dipakai sebagai username, jadi harus unik
+	var exists int
+	config.DB.QueryRow("SELECT COUNT(*) FROM users WHERE username = ? OR email = ?", req.Email, req.Email).Scan(&exists)
+	if exists > 0 {
+		http.Error(w, "Email sudah terdaftar", http.StatusConflict)
+		return
+	}
+
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := config.DB.Exec("INSERT INTO users (username, password, role, full_name, email, phone) VALUES (?, ?, ?, ?, ?, ?)",
+		req.Email, hash, models.RoleCustomer, req.Name, req.Email, req.Phone)
+	if err != nil {
+		// Registrasi bersamaan dengan email yang sama lolos cek di atas, tapi ditolak UNIQUE index
+		if utils.IsDuplicateEntry(err) {
+			http.Error(w, "Email sudah terdaftar", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	user := models.User{
+		ID:       int(id),
+		Username: req.Email,
+		Role:     models.RoleCustomer,
+	}
+
+	// Registrasi sukses langsung dianggap login
+	sendLoginResponse(w, http.StatusCreated, user, "Registrasi Berhasil")
+}
+
+// 2. PROFIL CUSTOMER (URL: /api/me)
+func ProfileHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	userID := currentUserID(r)
+
+	switch r.Method {
+	case "GET":
+		getProfile(w, userID)
+	case "PUT":
+		updateProfile(w, r, userID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func getProfile(w http.ResponseWriter, userID int) {
+	var user models.User
+	var fullName, email, phone sql.NullString
+	row := config.DB.QueryRow("SELECT id, username, role, full_name, email, phone, created_at FROM users WHERE id = ?", userID)
+
+	err := row.Scan(&user.ID, &user.Username, &user.Role, &fullName, &email, &phone, &user.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	user.FullName = fullName.String
+	user.Email = email.String
+	user.Phone = phone.String
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+func updateProfile(w http.ResponseWriter, r *http.Request, userID int) {
+	var req models.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Email tidak bisa diubah di sini karena dipakai sebagai username login
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "Nama wajib diisi", http.StatusBadRequest)
+		return
+	}
+
+	_, err := config.DB.Exec("UPDATE users SET full_name=?, phone=? WHERE id=?", req.Name, strings.TrimSpace(req.Phone), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	getProfile(w, userID)
+}
+
+// 3. ALAMAT TERSIMPAN (URL: /api/me/addresses)
+func AddressesHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	userID := currentUserID(r)
+
+	switch r.Method {
+	case "GET":
+		getAddresses(w, userID)
+	case "POST":
+		createAddress(w, r, userID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// 4. UPDATE & DELETE ALAMAT (URL: /api/me/addresses/{id})
+func AddressDetailHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	id, action, ok := parseIDPath(r.URL.Path, "/api/me/addresses/")
+	if !ok || action != "" {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	userID := currentUserID(r)
+
+	switch r.Method {
+	case "PUT":
+		updateAddress(w, r, userID, id)
+	case "DELETE":
+		deleteAddress(w, userID, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func validateAddress(a *models.Address) string {
+	a.Label = strings.TrimSpace(a.Label)
+	a.RecipientName = strings.TrimSpace(a.RecipientName)
+	a.Phone = strings.TrimSpace(a.Phone)
+	a.Address = strings.TrimSpace(a.Address)
+
+	if a.RecipientName == "" || a.Phone == "" || a.Address == "" {
+		return "Nama penerima, nomor HP dan alamat wajib diisi"
+	}
+	return ""
+}
+
+func getAddresses(w http.ResponseWriter, userID int) {
+	rows, err := config.DB.Query(`
+		SELECT id, user_id, label, recipient_name, phone, address, is_default, created_at
+		FROM customer_addresses
+		WHERE user_id = ?
+		ORDER BY is_default DESC, id ASC`, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	addresses := []models.Address{}
+	for rows.Next() {
+		var a models.Address
+		if err := rows.Scan(&a.ID, &a.UserID, &a.Label, &a.RecipientName, &a.Phone, &a.Address, &a.IsDefault, &a.CreatedAt); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		addresses = append(addresses, a)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(addresses)
+}
+
+// Ambil satu alamat milik customer (dipakai juga saat checkout)
+func getAddress(userID, id int) (models.Address, error) {
+	var a models.Address
+	row := config.DB.QueryRow(`
+		SELECT id, user_id, label, recipient_name, phone, address, is_default, created_at
+		FROM customer_addresses
+		WHERE id = ? AND user_id = ?`, id, userID)
+	err := row.Scan(&a.ID, &a.UserID, &a.Label, &a.RecipientName, &a.Phone, &a.Address, &a.IsDefault, &a.CreatedAt)
+	return a, err
+}
+
+func createAddress(w http.ResponseWriter, r *http.Request, userID int) {
+	var a models.Address
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if msg := validateAddress(&a); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Alamat pertama otomatis jadi alamat utama
+	var count int
+	tx.QueryRow("SELECT COUNT(*) FROM customer_addresses WHERE user_id = ?", userID).Scan(&count)
+	if count == 0 {
+		a.IsDefault = true
+	}
+
+	// Hanya boleh ada satu alamat utama
+	if a.IsDefault {
+		if _, err := tx.Exec("UPDATE customer_addresses SET is_default = 0 WHERE user_id = ?", userID); err != nil {
+			tx.Rollback()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	result, err := tx.Exec("INSERT INTO customer_addresses (user_id, label, recipient_name, phone, address, is_default) VALUES (?, ?, ?, ?, ?, ?)",
+		userID, a.Label, a.RecipientName, a.Phone, a.Address, a.IsDefault)
+	if err != nil {
+		tx.Rollback()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	created, err := getAddress(userID, int(id))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func updateAddress(w http.ResponseWriter, r *http.Request, userID, id int) {
+	var a models.Address
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if msg := validateAddress(&a); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	// Pastikan alamat memang milik customer ini
+	if _, err := getAddress(userID, id); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Address not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if a.IsDefault {
+		if _, err := tx.Exec("UPDATE customer_addresses SET is_default = 0 WHERE user_id = ?", userID); err != nil {
+			tx.Rollback()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// is_default hanya bisa dipindah (diset true di alamat lain), tidak bisa dicabut langsung
+	_, err = tx.Exec(`UPDATE customer_addresses
+		SET label=?, recipient_name=?, phone=?, address=?, is_default = (is_default OR ?)
+		WHERE id=? AND user_id=?`,
+		a.Label, a.RecipientName, a.Phone, a.Address, a.IsDefault, id, userID)
+	if err != nil {
+		tx.Rollback()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := getAddress(userID, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func deleteAddress(w http.ResponseWriter, userID, id int) {
+	result, err := config.DB.Exec("DELETE FROM customer_addresses WHERE id=? AND user_id=?", id, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		http.Error(w, "Address not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Address deleted successfully"})
+}
+
+// 5. RIWAYAT PESANAN CUSTOMER (URL: /api/me/orders)
+func MyOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rows, err := config.DB.Query(`
+		SELECT id, order_code, customer_name, customer_email, customer_phone, customer_address,
+		       total_amount, status, payment_method, created_at
+		FROM transactions
+		WHERE customer_id = ?
+		ORDER BY created_at DESC
+	`, currentUserID(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	transactions := []models.Transaction{}
+	for rows.Next() {
+		var t models.Transaction
+		var email, phone, address sql.NullString
+
+		if err := rows.Scan(&t.ID, &t.OrderCode, &t.CustomerName, &email, &phone, &address,
+			&t.TotalAmount, &t.Status, &t.PaymentMethod, &t.Date); err != nil {
+			log.Println("Scan error:", err)
+			continue
+		}
+		t.StatusName = models.StatusName(t.Status)
+		t.CustomerEmail = email.String
+		t.CustomerPhone = phone.String
+		t.Address = address.String
+
+		transactions = append(transactions, t)
+	}
+
+	if err := attachTransactionDetails(transactions); err != nil {
+		log.Println("Detail query error:", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transactions)
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"be/config"
+	"be/models"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// OrderExpiryWorker membatalkan pesanan yang belum dibayar melewati Window,
+// sekaligus mengembalikan stoknya (lewat changeTransactionStatus).
+type OrderExpiryWorker struct {
+	Window   time.Duration    // Batas waktu pembayaran
+	Interval time.Duration    // Jeda antar pengecekan
+	Now      func() time.Time // Sumber waktu, bisa diganti untuk testing
+}
+
+func NewOrderExpiryWorker(window, interval time.Duration) *OrderExpiryWorker {
+	return &OrderExpiryWorker{
+		Window:   window,
+		Interval: interval,
+		Now:      time.Now,
+	}
+}
+
+// Start menjalankan worker di background sampai ctx dibatalkan.
+func (ew *OrderExpiryWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(ew.Interval)
+		defer ticker.Stop()
+
+		for {
+			if count, err := ew.RunOnce(); err != nil {
+				log.Println("Order expiry error:", err)
+			} else if count > 0 {
+				log.Printf("Order expiry: %d pesanan dibatalkan otomatis\n", count)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce membatalkan semua pesanan kedaluwarsa saat ini dan mengembalikan jumlahnya.
+func (ew *OrderExpiryWorker) RunOnce() (int, error) {
+	deadline := ew.Now().Add(-ew.Window)
+
+	rows, err := config.DB.Query("SELECT id FROM transactions WHERE status = ? AND created_at < ? ORDER BY id ASC",
+		models.StatusPendingPayment, deadline)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	note := fmt.Sprintf("Dibatalkan otomatis: pembayaran tidak diterima dalam %g jam", ew.Window.Hours())
+
+	cancelled := 0
+	for _, id := range ids {
+		ok, err := ew.expire(id, deadline, note)
+		if err != nil {
+			log.Printf("Order expiry: gagal membatalkan pesanan %d: %v\n", id, err)
+			continue
+		}
+		if ok {
+			cancelled++
+		}
+	}
+	return cancelled, nil
+}
+
+// expire membatalkan satu pesanan jika (setelah dikunci) ternyata masih menunggu pembayaran.
+// Pesanan yang baru saja dibayar di antara SELECT dan lock tidak ikut dibatalkan.
+func (ew *OrderExpiryWorker) expire(id int, deadline time.Time, note string) (bool, error) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	var status int
+	var createdAt time.Time
+	err = tx.QueryRow("SELECT status, created_at FROM transactions WHERE id = ? FOR UPDATE", id).Scan(&status, &createdAt)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if status != models.StatusPendingPayment || !createdAt.Before(deadline) {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if _, err := changeTransactionStatus(tx, id, models.StatusCancelled, nil, note); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
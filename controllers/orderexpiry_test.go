@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"be/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestOrderExpiryRunOnce(t *testing.T) {
+	mock := mockDB(t)
+
+	now := time.Date(2025, 1, 30, 12, 0, 0, 0, time.UTC)
+	worker := NewOrderExpiryWorker(24*time.Hour, 5*time.Minute)
+	worker.Now = func() time.Time { return now }
+	deadline := now.Add(-24 * time.Hour)
+
+	// Kandidat: pesanan 1 (masih pending) dan pesanan 2 (dibayar setelah SELECT ini)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM transactions WHERE status = ? AND created_at < ?")).
+		WithArgs(models.StatusPendingPayment, deadline).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+
+	// Pesanan 1: masih pending setelah dikunci -> dibatalkan, riwayat dicatat, stok dikembalikan
+	lockQuery := regexp.QuoteMeta("SELECT status, created_at FROM transactions WHERE id = ? FOR UPDATE")
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockQuery).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "created_at"}).AddRow(models.StatusPendingPayment, deadline.Add(-time.Hour)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM transactions WHERE id = ? FOR UPDATE")).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(models.StatusPendingPayment))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE transactions SET status = ? WHERE id = ?")).
+		WithArgs(models.StatusCancelled, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO transaction_status_history").
+		WithArgs(1, models.StatusPendingPayment, models.StatusCancelled, nil, "Dibatalkan otomatis: pembayaran tidak diterima dalam 24 jam", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE transactions SET stock_restored = 1 WHERE id = ? AND stock_restored = 0")).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE books b\s+JOIN .*transaction_details.*SET b.stock = b.stock \+ d.qty`).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	// Pesanan 2: sudah dibayar saat dikunci -> dilewati tanpa perubahan
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockQuery).WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "created_at"}).AddRow(models.StatusPaid, deadline.Add(-time.Hour)))
+	mock.ExpectRollback()
+
+	cancelled, err := worker.RunOnce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled != 1 {
+		t.Errorf("cancelled = %d, want 1", cancelled)
+	}
+}
+
+// Pesanan yang created_at-nya ternyata belum melewati batas (misal jam berubah) tidak dibatalkan.
+func TestOrderExpirySkipsOrderNotYetExpired(t *testing.T) {
+	mock := mockDB(t)
+
+	now := time.Date(2025, 1, 30, 12, 0, 0, 0, time.UTC)
+	worker := NewOrderExpiryWorker(time.Hour, time.Minute)
+	worker.Now = func() time.Time { return now }
+
+	mock.ExpectQuery("SELECT id FROM transactions").
+		WithArgs(models.StatusPendingPayment, now.Add(-time.Hour)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE").WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "created_at"}).AddRow(models.StatusPendingPayment, now.Add(-30*time.Minute)))
+	mock.ExpectRollback()
+
+	cancelled, err := worker.RunOnce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled != 0 {
+		t.Errorf("cancelled = %d, want 0", cancelled)
+	}
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"be/config"
+	"be/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	errTransactionNotFound = errors.New("transaction not found")
+	errIllegalTransition   = errors.New("illegal status transition")
+)
+
+// changeTransactionStatus memindahkan status pesanan di dalam DB transaction 'tx'
+// dan mencatatnya di riwayat status. actorID boleh nil (perubahan oleh sistem).
+// Baris pesanan dikunci (FOR UPDATE) agar dua admin tidak mengubah status bersamaan.
+// Mengembalikan status sebelumnya. Jika status sama dengan sekarang, tidak ada yang diubah.
+func changeTransactionStatus(tx *sql.Tx, id, to int, actorID *int, note string) (int, error) {
+	var from int
+	err := tx.QueryRow("SELECT status FROM transactions WHERE id = ? FOR UPDATE", id).Scan(&from)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errTransactionNotFound
+		}
+		return 0, err
+	}
+
+	if from == to {
+		return from, nil
+	}
+	if !models.CanTransition(from, to) {
+		return from, errIllegalTransition
+	}
+
+	if _, err := tx.Exec("UPDATE transactions SET status = ? WHERE id = ?", to, id); err != nil {
+		return from, err
+	}
+
+	if err := recordStatusHistory(tx, id, &from, to, actorID, note); err != nil {
+		return from, err
+	}
+
+	// Pesanan batal / refund -> stok buku dikembalikan
+	if to == models.StatusCancelled || to == models.StatusRefunded {
+		if err := restoreStock(tx, id); err != nil {
+			return from, err
+		}
+	}
+	return from, nil
+}
+
+// restoreStock mengembalikan jumlah buku di transaction_details ke books.stock.
+// Flag stock_restored memastikan stok hanya dikembalikan sekali per pesanan,
+// misal saat pesanan dibatalkan lalu ditandai refund.
+func restoreStock(tx *sql.Tx, transactionID int) error {
+	result, err := tx.Exec("UPDATE transactions SET stock_restored = 1 WHERE id = ? AND stock_restored = 0", transactionID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil // Sudah pernah dikembalikan
+	}
+
+	_, err = tx.Exec(`
+		UPDATE books b
+		JOIN (
+			SELECT book_id, SUM(quantity) AS qty
+			FROM transaction_details
+			WHERE transaction_id = ?
+			GROUP BY book_id
+		) d ON b.id = d.book_id
+		SET b.stock = b.stock + d.qty`, transactionID)
+	return err
+}
+
+// recordStatusHistory menambah satu baris riwayat status. from nil = pesanan baru dibuat.
+func recordStatusHistory(tx *sql.Tx, transactionID int, from *int, to int, actorID *int, note string) error {
+	_, err := tx.Exec("INSERT INTO transaction_status_history (transaction_id, from_status, to_status, changed_by, note, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		transactionID, from, to, actorID, note, time.Now())
+	return err
+}
+
+// getStatusHistory mengambil timeline status satu pesanan, urut dari yang paling lama.
+func getStatusHistory(transactionID int) ([]models.StatusHistory, error) {
+	rows, err := config.DB.Query(`
+		SELECT h.id, h.from_status, h.to_status, h.changed_by, u.username, h.note, h.created_at
+		FROM transaction_status_history h
+		LEFT JOIN users u ON h.changed_by = u.id
+		WHERE h.transaction_id = ?
+		ORDER BY h.created_at ASC, h.id ASC`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.StatusHistory{}
+	for rows.Next() {
+		var h models.StatusHistory
+		var from, changedBy sql.NullInt64
+		var changedByName sql.NullString
+
+		if err := rows.Scan(&h.ID, &from, &h.ToStatus, &changedBy, &changedByName, &h.Note, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if from.Valid {
+			status := int(from.Int64)
+			h.FromStatus = &status
+			h.FromStatusName = models.StatusName(status)
+		}
+		if changedBy.Valid {
+			userID := int(changedBy.Int64)
+			h.ChangedBy = &userID
+			h.ChangedByName = changedByName.String
+		}
+		h.ToStatusName = models.StatusName(h.ToStatus)
+
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
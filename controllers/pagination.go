@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"be/models"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// parsePagination membaca ?page= dan ?limit= dari URL.
+// Nilai kosong / tidak valid memakai default, limit dibatasi maxLimit.
+func parsePagination(r *http.Request, defaultLimit, maxLimit int) (int, int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	return page, limit
+}
+
+func newPageMeta(page, limit, total int) models.PageMeta {
+	totalPages := (total + limit - 1) / limit
+
+	meta := models.PageMeta{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+	if page < totalPages {
+		next := page + 1
+		meta.NextPage = &next
+	}
+	return meta
+}
+
+// setPageHeaders menulis info pagination ke header, untuk endpoint yang response-nya tetap array biasa.
+func setPageHeaders(w http.ResponseWriter, meta models.PageMeta) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(meta.Total))
+	w.Header().Set("X-Page", strconv.Itoa(meta.Page))
+	w.Header().Set("X-Limit", strconv.Itoa(meta.Limit))
+	w.Header().Set("X-Total-Pages", strconv.Itoa(meta.TotalPages))
+}
+
+// likeContains membuat pola LIKE "%kata%" dengan karakter wildcard (%, _) dari input di-escape.
+func likeContains(term string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)
+	return "%" + escaped + "%"
+}
+
+// inClause membuat placeholder "?,?,?" beserta argumennya untuk query "... IN (...)".
+func inClause(ids []int) (string, []interface{}) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return strings.Join(placeholders, ","), args
+}
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"be/config"
+	"be/models"
+	"be/notifier"
+	"be/utils"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Notifier untuk mengirim link reset password, diset di main.go
+var Notifier notifier.Notifier = notifier.NewLogNotifier("")
+
+// 1. MINTA RESET PASSWORD (URL: /api/password/forgot)
+func ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Response selalu sama, agar tidak bisa dipakai untuk mengecek email mana yang terdaftar
+	resp := map[string]string{"message": "Jika email terdaftar, link reset password sudah dikirim"}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" {
+		http.Error(w, "Email wajib diisi", http.StatusBadRequest)
+		return
+	}
+
+	var userID int
+	var name sql.NullString
+	err := config.DB.QueryRow("SELECT id, full_name FROM users WHERE email = ? AND is_disabled = 0", email).Scan(&userID, &name)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Println("Forgot password query error:", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	token, hash, err := utils.NewRandomToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	expiresAt := time.Now().Add(config.PasswordResetTTL)
+
+	// Token lama yang belum terpakai dibatalkan, hanya link terbaru yang berlaku
+	if _, err := config.DB.Exec("UPDATE password_resets SET used_at = NOW() WHERE user_id = ? AND used_at IS NULL", userID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = config.DB.Exec("INSERT INTO password_resets (user_id, token_hash, expires_at) VALUES (?, ?, ?)", userID, hash, expiresAt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	link := config.PasswordResetURL + "?token=" + url.QueryEscape(token)
+	if err := Notifier.SendPasswordReset(email, name.String, link, expiresAt); err != nil {
+		log.Println("Gagal kirim link reset password:", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// 2. KONFIRMASI RESET PASSWORD (URL: /api/password/reset)
+func ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.ConfirmResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Token == "" {
+		http.Error(w, "Token wajib diisi", http.StatusBadRequest)
+		return
+	}
+	if !validPassword(req.Password) {
+		http.Error(w, "Password minimal 8 karakter", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Waktu pembanding dari Go (bukan NOW() MySQL), sama seperti saat expires_at ditulis,
+	// agar link tidak langsung kedaluwarsa jika zona waktu server DB bukan UTC
+	var resetID, userID int
+	var username string
+	row := tx.QueryRow(`
+		SELECT pr.id, pr.user_id, u.username
+		FROM password_resets pr
+		JOIN users u ON pr.user_id = u.id
+		WHERE pr.token_hash = ? AND pr.used_at IS NULL AND pr.expires_at > ?
+		FOR UPDATE`, utils.HashToken(req.Token), time.Now())
+	if err := row.Scan(&resetID, &userID, &username); err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			http.Error(w, "Link reset password tidak valid atau sudah kedaluwarsa", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Tandai token sudah dipakai (sekali pakai)
+	if _, err := tx.Exec("UPDATE password_resets SET used_at = NOW() WHERE id = ?", resetID); err != nil {
+		tx.Rollback()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := tx.Exec("UPDATE users SET password = ? WHERE id = ?", hash, userID); err != nil {
+		tx.Rollback()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Semua sesi lama dicabut
+	if _, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL", userID); err != nil {
+		tx.Rollback()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Password baru -> akun yang terkunci karena salah password dibuka lagi
+	accountGuard.Reset(strings.ToLower(username))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password berhasil diubah, silakan login"})
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseIDPath memecah path seperti "/api/users/5" atau "/api/users/5/password"
+// menjadi ID (5) dan sub-resource ("" atau "password").
+func parseIDPath(path, prefix string) (int, string, bool) {
+	rest := strings.Trim(strings.TrimPrefix(path, prefix), "/")
+	parts := strings.Split(rest, "/")
+	if len(parts) > 2 {
+		return 0, "", false
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || id <= 0 {
+		return 0, "", false
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	return id, action, true
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"be/config"
+	"be/models"
+	"be/search"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Index pencarian buku (in-memory), diisi saat server start lewat BuildSearchIndex
+// dan diperbarui setiap kali buku dibuat / diubah / diarsipkan / di-restore.
+var searchIndex = search.NewIndex()
+
+// BuildSearchIndex memuat semua buku aktif ke index pencarian.
+func BuildSearchIndex() error {
+	rows, err := config.DB.Query("SELECT " + bookColumns + " FROM books WHERE deleted_at IS NULL")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	books := []models.Book{}
+	for rows.Next() {
+		book, err := scanBook(rows)
+		if err != nil {
+			return err
+		}
+		books = append(books, book)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := attachBookRelations(books); err != nil {
+		return err
+	}
+	for _, book := range books {
+		searchIndex.Add(book)
+	}
+	return nil
+}
+
+// reindexBook menyinkronkan satu buku dengan index: buku aktif dimasukkan, buku arsip dihapus.
+func reindexBook(id int) {
+	book, err := scanBook(config.DB.QueryRow("SELECT "+bookColumns+" FROM books WHERE id = ?", id))
+	if err != nil || book.DeletedAt != nil {
+		searchIndex.Remove(id)
+		return
+	}
+
+	books := []models.Book{book}
+	attachBookRelations(books)
+	searchIndex.Add(books[0])
+}
+
+// Hasil pencarian: data buku terbaru dari DB + skor relevansi
+type bookSearchResult struct {
+	models.Book
+	Score float64 `json:"score"`
+}
+
+// 1. PENCARIAN RELEVANSI (URL: /api/books/search?q=...&page=&limit=)
+func BookSearchHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "Parameter q wajib diisi", http.StatusBadRequest)
+		return
+	}
+
+	hits := searchIndex.Search(query)
+	page, limit := parsePagination(r, 24, 100)
+
+	// Potong hasil sesuai halaman
+	start := min((page-1)*limit, len(hits))
+	end := min(start+limit, len(hits))
+	pageHits := hits[start:end]
+
+	results := []bookSearchResult{}
+	if len(pageHits) > 0 {
+		// Data buku (stok, harga) diambil dari DB agar selalu terbaru
+		ids := make([]int, len(pageHits))
+		for i, hit := range pageHits {
+			ids[i] = hit.BookID
+		}
+
+		placeholders, args := inClause(ids)
+		rows, err := config.DB.Query("SELECT "+bookColumns+" FROM books WHERE id IN ("+placeholders+") AND deleted_at IS NULL", args...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		var found []models.Book
+		for rows.Next() {
+			book, err := scanBook(rows)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			found = append(found, book)
+		}
+
+		if err := attachBookRelations(found); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		books := map[int]models.Book{}
+		for _, book := range found {
+			books[book.ID] = book
+		}
+
+		// Urutan tetap mengikuti relevansi
+		for _, hit := range pageHits {
+			if book, ok := books[hit.BookID]; ok {
+				results = append(results, bookSearchResult{Book: book, Score: hit.Score})
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.PagedResponse{
+		Data: results,
+		Meta: newPageMeta(page, limit, len(hits)),
+	})
+}
+
+// 2. AUTOCOMPLETE (URL: /api/books/suggest?q=...&limit=)
+func BookSuggestHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 || limit > 20 {
+		limit = 8
+	}
+
+	suggestions := searchIndex.Suggest(r.URL.Query().Get("q"), limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
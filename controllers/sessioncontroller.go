@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"be/config"
+	"be/models"
+	"be/utils"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// createSession menyimpan refresh token baru (hanya hash-nya) untuk user.
+func createSession(userID int) (int, string, time.Time, error) {
+	token, hash, err := utils.NewRandomToken()
+	if err != nil {
+		return 0, "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(config.RefreshTokenTTL)
+	result, err := config.DB.Exec("INSERT INTO refresh_tokens (user_id, token_hash, expires_at) VALUES (?, ?, ?)",
+		userID, hash, expiresAt)
+	if err != nil {
+		return 0, "", time.Time{}, err
+	}
+
+	id, _ := result.LastInsertId()
+	return int(id), token, expiresAt, nil
+}
+
+// revokeUserSessions mencabut semua sesi aktif milik user (logout dari semua perangkat).
+func revokeUserSessions(userID int) error {
+	_, err := config.DB.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL", userID)
+	return err
+}
+
+// 1. REFRESH ACCESS TOKEN (URL: /api/refresh)
+// Refresh token lama langsung dicabut dan diganti yang baru (rotation).
+func RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "Refresh token wajib diisi", http.StatusBadRequest)
+		return
+	}
+
+	var sessionID int
+	var expiresAt time.Time
+	var revokedAt sql.NullTime
+	var user models.User
+	row := config.DB.QueryRow(`
+		SELECT rt.id, rt.expires_at, rt.revoked_at, u.id, u.username, u.role, u.is_disabled
+		FROM refresh_tokens rt
+		JOIN users u ON rt.user_id = u.id
+		WHERE rt.token_hash = ?`, utils.HashToken(req.RefreshToken))
+
+	err := row.Scan(&sessionID, &expiresAt, &revokedAt, &user.ID, &user.Username, &user.Role, &user.Disabled)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Refresh token tidak valid", http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Token yang sudah dicabut dipakai lagi = kemungkinan dicuri, cabut semua sesi user ini
+	if revokedAt.Valid {
+		if err := revokeUserSessions(user.ID); err != nil {
+			log.Println("Gagal revoke sesi user:", err)
+		}
+		http.Error(w, "Refresh token sudah tidak berlaku, silakan login ulang", http.StatusUnauthorized)
+		return
+	}
+
+	if time.Now().After(expiresAt) {
+		http.Error(w, "Refresh token sudah kedaluwarsa, silakan login ulang", http.StatusUnauthorized)
+		return
+	}
+
+	if user.Disabled {
+		http.Error(w, "Akun dinonaktifkan, hubungi admin", http.StatusForbidden)
+		return
+	}
+
+	// Cabut token lama. Cek RowsAffected agar dua request refresh bersamaan tidak sama-sama lolos.
+	result, err := config.DB.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = ? AND revoked_at IS NULL", sessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		http.Error(w, "Refresh token sudah tidak berlaku, silakan login ulang", http.StatusUnauthorized)
+		return
+	}
+
+	sendLoginResponse(w, http.StatusOK, user, "Token diperbarui")
+}
+
+// 2. LOGOUT (URL: /api/logout)
+// Mencabut refresh token yang dikirim. Selalu sukses agar client bisa membersihkan sesinya.
+func LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "Refresh token wajib diisi", http.StatusBadRequest)
+		return
+	}
+
+	_, err := config.DB.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE token_hash = ? AND revoked_at IS NULL",
+		utils.HashToken(req.RefreshToken))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Logout berhasil"})
+}
+
+// 3. REVOKE SEMUA SESI USER (URL: DELETE /api/users/{id}/sessions, khusus admin)
+func revokeSessionsHandler(w http.ResponseWriter, id int) {
+	if err := revokeUserSessions(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "All sessions revoked successfully"})
+}
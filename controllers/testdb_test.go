@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"be/config"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// mockDB mengganti config.DB dengan sqlmock untuk unit test tanpa database.
+// Query dicocokkan berurutan; query yang tidak diharapkan membuat test gagal.
+func mockDB(t *testing.T) sqlmock.Sqlmock {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldDB := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+		config.DB = oldDB
+	})
+	return mock
+}
+
+// Tabel dasar yang sudah ada sebelum config.Migrate (dibuat manual di server production).
+var baseSchema = []string{
+	`CREATE TABLE IF NOT EXISTS users (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		username VARCHAR(100) NOT NULL,
+		password VARCHAR(100) NOT NULL,
+		role VARCHAR(20) NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS books (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		title VARCHAR(255) NOT NULL,
+		author VARCHAR(255) NOT NULL,
+		price DECIMAL(12,2) NOT NULL,
+		category VARCHAR(100) NOT NULL DEFAULT '',
+		stock INT NOT NULL DEFAULT 0,
+		image_url VARCHAR(255) NOT NULL DEFAULT '',
+		description TEXT
+	)`,
+	`CREATE TABLE IF NOT EXISTS transactions (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		order_code VARCHAR(50) NOT NULL,
+		customer_name VARCHAR(150) NOT NULL,
+		customer_email VARCHAR(150) NULL,
+		customer_phone VARCHAR(30) NULL,
+		customer_address TEXT NULL,
+		payment_method VARCHAR(50) NOT NULL,
+		total_amount DECIMAL(12,2) NOT NULL,
+		status INT NOT NULL,
+		created_at DATETIME NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS transaction_details (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		transaction_id INT NOT NULL,
+		book_id INT NOT NULL,
+		quantity INT NOT NULL,
+		price_at_purchase DECIMAL(12,2) NOT NULL
+	)`,
+}
+
+// openTestDB menghubungkan config.DB ke database MySQL khusus test dari env TEST_DATABASE_DSN
+// (contoh: root:@tcp(127.0.0.1:3306)/bookthree_test?parseTime=true), membuat tabel dasar,
+// lalu menjalankan migrasi. Test di-skip jika env tidak diisi.
+// Harus MySQL/MariaDB asli (InnoDB), karena test checkout paralel bergantung pada row lock.
+// JANGAN arahkan ke database production: test menulis data sendiri.
+func openTestDB(t *testing.T) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN tidak diisi, test integrasi MySQL dilewati")
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	oldDB := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		db.Close()
+		config.DB = oldDB
+	})
+
+	for _, query := range baseSchema {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config.Migrate()
+}
@@ -2,12 +2,13 @@ package controllers
 
 import (
 	"be/config"
+	"be/middleware"
 	"be/models"
+	"be/utils"
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"log"
-	"math/rand"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -32,40 +33,114 @@ func CheckoutHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// --- 1. GENERATE ORDER CODE DI BACKEND ---
-	// Format: B3-YYYYMMDD-XXXX (4 digit random angka/huruf)
+	// Jika checkout dalam keadaan login sebagai customer, hubungkan pesanan ke akunnya
+	if claims, ok := middleware.UserFromContext(r.Context()); ok && claims.Role == models.RoleCustomer {
+		customerID := claims.UserID
+		txData.CustomerID = &customerID
 
-	// Seed random number generator (agar acak setiap waktu)
-	rand.Seed(time.Now().UnixNano())
-
-	// Buat komponen tanggal: 20250130
-	dateStr := time.Now().Format("20060102")
+		// Pakai alamat tersimpan jika dipilih
+		if txData.AddressID > 0 {
+			addr, err := getAddress(customerID, txData.AddressID)
+			if err != nil {
+				http.Error(w, "Alamat tidak ditemukan", http.StatusBadRequest)
+				return
+			}
+			txData.CustomerName = addr.RecipientName
+			txData.CustomerPhone = addr.Phone
+			txData.Address = addr.Address
+		}
 
-	// Buat komponen random: Angka 1000-9999
-	randomNum := rand.Intn(9000) + 1000
+		// Email diambil dari profil jika tidak diisi
+		if txData.CustomerEmail == "" {
+			var email sql.NullString
+			config.DB.QueryRow("SELECT email FROM users WHERE id = ?", customerID).Scan(&email)
+			txData.CustomerEmail = email.String
+		}
+	} else {
+		txData.CustomerID = nil
+	}
 
-	// Gabungkan menjadi Order Code
-	generatedOrderCode := fmt.Sprintf("B3-%s-%d", dateStr, randomNum)
+	if len(txData.Details) == 0 {
+		http.Error(w, "Keranjang kosong", http.StatusBadRequest)
+		return
+	}
+	for _, item := range txData.Details {
+		if item.BookID <= 0 || item.Quantity <= 0 {
+			http.Error(w, "Book ID dan jumlah harus lebih dari 0", http.StatusBadRequest)
+			return
+		}
+	}
 
-	// --- 2. MULAI DATABASE TRANSACTION ---
+	// --- 1. MULAI DATABASE TRANSACTION ---
 	tx, err := config.DB.Begin()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Step 1: Insert Header Transaksi
-	// PERHATIKAN: Kita menggunakan 'generatedOrderCode' di sini, BUKAN 'txData.OrderCode'
-	res, err := tx.Exec("INSERT INTO transactions (order_code, customer_name, customer_email, customer_phone, customer_address, payment_method, total_amount, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
-		generatedOrderCode,
-		txData.CustomerName,
-		txData.CustomerEmail, // <--- Masukkan Email
-		txData.CustomerPhone,
-		txData.Address,
-		txData.PaymentMethod,
-		txData.TotalAmount,
-		100,
-		time.Now())
+	// Step 1: Hitung harga & total di server (harga dari browser tidak dipercaya)
+	items, total, missing, err := priceCheckoutItems(tx, txData.Details)
+	if err != nil {
+		tx.Rollback()
+		http.Error(w, "Gagal cek harga buku: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(missing) > 0 {
+		tx.Rollback()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":  "Beberapa buku tidak ditemukan",
+			"book_ids": missing,
+		})
+		return
+	}
+
+	// Harga / total yang dikirim browser berbeda dengan harga saat ini (misal harga sudah berubah)
+	if mismatch := checkoutMismatch(txData, items, total); mismatch {
+		tx.Rollback()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":      "Harga atau total tidak sesuai dengan harga terbaru, silakan cek ulang keranjang",
+			"items":        items,
+			"total_amount": total,
+		})
+		return
+	}
+
+	// Step 2: Insert Header Transaksi
+	// Order code digenerate di backend (BUKAN 'txData.OrderCode'), dan total
+	// memakai 'total' hasil hitungan server (BUKAN 'txData.TotalAmount')
+	var generatedOrderCode string
+	var res sql.Result
+	for attempt := 1; ; attempt++ {
+		generatedOrderCode, err = utils.GenerateOrderCode(time.Now())
+		if err != nil {
+			tx.Rollback()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		res, err = tx.Exec("INSERT INTO transactions (order_code, customer_id, customer_name, customer_email, customer_phone, customer_address, payment_method, total_amount, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			generatedOrderCode,
+			txData.CustomerID,
+			txData.CustomerName,
+			txData.CustomerEmail, // <--- Masukkan Email
+			txData.CustomerPhone,
+			txData.Address,
+			txData.PaymentMethod,
+			total,
+			models.StatusPendingPayment,
+			time.Now())
+
+		// Order code bentrok dengan yang sudah ada (sangat jarang) -> generate ulang
+		if err != nil && utils.IsDuplicateEntry(err) && attempt < 5 {
+			continue
+		}
+		break
+	}
 
 	if err != nil {
 		tx.Rollback()
@@ -75,27 +150,52 @@ func CheckoutHandler(w http.ResponseWriter, r *http.Request) {
 
 	txID, _ := res.LastInsertId()
 
-	// Step 2: Insert Detail Buku
-	for _, item := range txData.Details {
-		_, err = tx.Exec("INSERT INTO transaction_details (transaction_id, book_id, quantity, price_at_purchase) VALUES (?, ?, ?, ?)",
-			txID, item.BookID, item.Quantity, item.Price)
+	// Catat status awal di riwayat
+	if err := recordStatusHistory(tx, int(txID), nil, models.StatusPendingPayment, txData.CustomerID, "Pesanan dibuat"); err != nil {
+		tx.Rollback()
+		http.Error(w, "Gagal simpan riwayat status: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
+	// Step 3: Kurangi stok secara atomik, hanya jika stok masih cukup.
+	// Semua item dicek dulu agar frontend tahu buku mana saja yang kurang.
+	var shortBookIDs []int
+	for _, item := range items {
+		result, err := tx.Exec("UPDATE books SET stock = stock - ? WHERE id = ? AND stock >= ?", item.Quantity, item.BookID, item.Quantity)
 		if err != nil {
 			tx.Rollback()
-			http.Error(w, "Gagal simpan detail: "+err.Error(), http.StatusInternalServerError)
+			http.Error(w, "Gagal update stok", http.StatusInternalServerError)
 			return
 		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			shortBookIDs = append(shortBookIDs, item.BookID)
+		}
+	}
+
+	if len(shortBookIDs) > 0 {
+		tx.Rollback()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":  "Stok tidak mencukupi",
+			"book_ids": shortBookIDs,
+		})
+		return
+	}
+
+	// Step 4: Insert Detail Buku (harga = harga dari tabel books, beserta snapshot judul/penulis/gambar)
+	for _, item := range items {
+		_, err = tx.Exec("INSERT INTO transaction_details (transaction_id, book_id, quantity, price_at_purchase, book_title, book_author, book_image) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			txID, item.BookID, item.Quantity, item.Price, item.Book.Title, item.Book.Author, item.Book.Image)
 
-		// Update Stok
-		_, err = tx.Exec("UPDATE books SET stock = stock - ? WHERE id = ?", item.Quantity, item.BookID)
 		if err != nil {
 			tx.Rollback()
-			http.Error(w, "Gagal update stok", http.StatusInternalServerError)
+			http.Error(w, "Gagal simpan detail: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 	}
 
-	// Step 3: Commit
+	// Step 5: Commit
 	err = tx.Commit()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -104,35 +204,236 @@ func CheckoutHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusCreated)
 
-	// --- 3. KIRIM KODE YANG DIGENERATE KE FRONTEND ---
+	// --- 2. KIRIM KODE YANG DIGENERATE KE FRONTEND ---
 	response := map[string]interface{}{
 		"message":        "Transaksi berhasil disimpan",
 		"order_code":     generatedOrderCode, // <--- Ini yang penting!
 		"transaction_id": txID,
+		"total_amount":   total,
 	}
 
 	json.NewEncoder(w).Encode(response)
 }
 
+// Bulatkan ke 2 desimal agar perbandingan float tidak meleset karena pembulatan
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// priceCheckoutItems mengambil harga terbaru dari tabel books (dikunci FOR UPDATE sampai commit),
+// menggabungkan buku yang sama, lalu menghitung total pesanan.
+// Book ID yang tidak ada di database (atau sudah diarsipkan) dikembalikan di 'missing'.
+func priceCheckoutItems(tx *sql.Tx, details []models.TransactionDetail) ([]models.TransactionDetail, float64, []int, error) {
+	// Gabungkan item dengan book_id yang sama, urutan tetap sesuai keranjang
+	var items []models.TransactionDetail
+	index := map[int]int{}
+	for _, d := range details {
+		if i, ok := index[d.BookID]; ok {
+			items[i].Quantity += d.Quantity
+			continue
+		}
+		index[d.BookID] = len(items)
+		items = append(items, models.TransactionDetail{BookID: d.BookID, Quantity: d.Quantity})
+	}
+
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.BookID
+	}
+
+	placeholders, args := inClause(ids)
+	rows, err := tx.Query("SELECT id, price, title, author, image_url FROM books WHERE id IN ("+placeholders+") AND deleted_at IS NULL FOR UPDATE", args...)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer rows.Close()
+
+	// Harga + data buku untuk snapshot di transaction_details
+	books := map[int]models.Book{}
+	for rows.Next() {
+		var b models.Book
+		if err := rows.Scan(&b.ID, &b.Price, &b.Title, &b.Author, &b.ImageURL); err != nil {
+			return nil, 0, nil, err
+		}
+		books[b.ID] = b
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, nil, err
+	}
+
+	var total float64
+	var missing []int
+	for i := range items {
+		book, ok := books[items[i].BookID]
+		if !ok {
+			missing = append(missing, items[i].BookID)
+			continue
+		}
+		items[i].Price = book.Price
+		items[i].Book.Title = book.Title
+		items[i].Book.Author = book.Author
+		items[i].Book.Image = book.ImageURL
+		total += book.Price * float64(items[i].Quantity)
+	}
+
+	return items, roundMoney(total), missing, nil
+}
+
+// checkoutMismatch bernilai true jika harga per item atau total yang dikirim browser
+// berbeda dengan hitungan server. Field yang tidak dikirim (0) tidak dicek.
+func checkoutMismatch(txData models.Transaction, items []models.TransactionDetail, total float64) bool {
+	if txData.TotalAmount != 0 && roundMoney(txData.TotalAmount) != total {
+		return true
+	}
+
+	prices := map[int]float64{}
+	for _, item := range items {
+		prices[item.BookID] = item.Price
+	}
+	for _, d := range txData.Details {
+		if d.Price != 0 && roundMoney(d.Price) != roundMoney(prices[d.BookID]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Kolom yang boleh dipakai untuk ?sort=
+var transactionSortColumns = map[string]string{
+	"created_at":   "created_at",
+	"total_amount": "total_amount",
+	"status":       "status",
+	"order_code":   "order_code",
+}
+
+// buildTransactionFilter menyusun WHERE clause dari query param:
+// status (kode atau nama, boleh dipisah koma), date_from & date_to (YYYY-MM-DD),
+// payment_method, email, phone. Mengembalikan pesan error jika ada param yang tidak valid.
+func buildTransactionFilter(r *http.Request) (string, []interface{}, string) {
+	q := r.URL.Query()
+	var conditions []string
+	var args []interface{}
+
+	if statusParam := q.Get("status"); statusParam != "" {
+		var statuses []int
+		for _, s := range strings.Split(statusParam, ",") {
+			s = strings.TrimSpace(s)
+			status, err := strconv.Atoi(s)
+			if err != nil {
+				var ok bool
+				if status, ok = models.StatusFromName(s); !ok {
+					return "", nil, "Status tidak dikenal: " + s
+				}
+			}
+			statuses = append(statuses, status)
+		}
+		placeholders, statusArgs := inClause(statuses)
+		conditions = append(conditions, "status IN ("+placeholders+")")
+		args = append(args, statusArgs...)
+	}
+
+	if dateFrom := q.Get("date_from"); dateFrom != "" {
+		from, err := time.Parse("2006-01-02", dateFrom)
+		if err != nil {
+			return "", nil, "Format date_from harus YYYY-MM-DD"
+		}
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, from)
+	}
+
+	if dateTo := q.Get("date_to"); dateTo != "" {
+		to, err := time.Parse("2006-01-02", dateTo)
+		if err != nil {
+			return "", nil, "Format date_to harus YYYY-MM-DD"
+		}
+		// date_to inklusif: sampai akhir hari tersebut
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, to.AddDate(0, 0, 1))
+	}
+
+	if method := q.Get("payment_method"); method != "" {
+		conditions = append(conditions, "payment_method = ?")
+		args = append(args, method)
+	}
+
+	if email := strings.TrimSpace(q.Get("email")); email != "" {
+		conditions = append(conditions, "customer_email LIKE ?")
+		args = append(args, likeContains(email))
+	}
+
+	if phone := strings.TrimSpace(q.Get("phone")); phone != "" {
+		conditions = append(conditions, "customer_phone LIKE ?")
+		args = append(args, likeContains(phone))
+	}
+
+	if len(conditions) == 0 {
+		return "", args, ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args, ""
+}
+
 // 2. GET ALL TRANSACTIONS (Untuk Admin Dashboard)
+// Query param: page, limit, sort (created_at|total_amount|status|order_code), order (asc|desc),
+// status, date_from, date_to, payment_method, email, phone
 func TransactionListHandler(w http.ResponseWriter, r *http.Request) {
 	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	where, args, errMsg := buildTransactionFilter(r)
+	if errMsg != "" {
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	sortColumn, ok := transactionSortColumns[r.URL.Query().Get("sort")]
+	if !ok {
+		sortColumn = "created_at"
+	}
+	sortOrder := "DESC"
+	if strings.EqualFold(r.URL.Query().Get("order"), "asc") {
+		sortOrder = "ASC"
+	}
+
+	// Response tetap array biasa (kompatibel dengan dashboard admin lama), sama seperti GET /api/books.
+	// Pagination hanya aktif jika ?page= / ?limit= dikirim, info total ada di header X-Total-Count dkk.
+	paginated := r.URL.Query().Has("page") || r.URL.Query().Has("limit")
+	page, limit := parsePagination(r, 20, 100)
 
 	// 1. Query Data Transaksi Utama
 	// Tambahkan customer_email di SELECT
-	rows, err := config.DB.Query(`
-		SELECT id, order_code, customer_name, customer_email, customer_phone, customer_address, 
-		       total_amount, status, payment_method, created_at 
-		FROM transactions 
-		ORDER BY created_at DESC
-	`)
+	query := `
+		SELECT id, order_code, customer_name, customer_email, customer_phone, customer_address,
+		       total_amount, status, payment_method, created_at
+		FROM transactions
+		` + where + `
+		ORDER BY ` + sortColumn + ` ` + sortOrder + `, id ` + sortOrder
+
+	var total int
+	if paginated {
+		// Hitung total data (untuk header pagination)
+		if err := config.DB.QueryRow("SELECT COUNT(*) FROM transactions "+where, args...).Scan(&total); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, (page-1)*limit)
+	}
+
+	rows, err := config.DB.Query(query, args...)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	defer rows.Close()
 
-	var transactions []models.Transaction
+	transactions := []models.Transaction{}
 
 	for rows.Next() {
 		var t models.Transaction
@@ -156,6 +457,7 @@ func TransactionListHandler(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		t.StatusName = models.StatusName(t.Status)
 		if email.Valid {
 			t.CustomerEmail = email.String
 		} // <--- Assign Email
@@ -166,84 +468,231 @@ func TransactionListHandler(w http.ResponseWriter, r *http.Request) {
 			t.Address = address.String
 		}
 
-		// --- 2. LOGIC AMBIL DETAIL BUKU (Nested Query) ---
-		detailRows, err := config.DB.Query(`
-			SELECT td.id, td.book_id, td.quantity, td.price_at_purchase, b.title, b.image_url
-			FROM transaction_details td
-			JOIN books b ON td.book_id = b.id
-			WHERE td.transaction_id = ?
-		`, t.ID)
-
-		if err != nil {
-			log.Println("Detail query error:", err) // Cek log ini jika masih error
-		} else {
-			var details []models.TransactionDetail
-			for detailRows.Next() {
-				var d models.TransactionDetail
-				var bookTitle, bookImage string
-
-				// Scan detail
-				err := detailRows.Scan(&d.ID, &d.BookID, &d.Quantity, &d.Price, &bookTitle, &bookImage)
-				if err != nil {
-					log.Println("Scan detail error:", err)
-					continue
-				}
-
-				d.Book.Title = bookTitle
-				d.Book.Image = bookImage
+		transactions = append(transactions, t)
+	}
 
-				details = append(details, d)
-			}
-			detailRows.Close()
-			t.Details = details
-		}
+	// --- 2. LOGIC AMBIL DETAIL BUKU ---
+	// Satu query untuk semua transaksi di halaman ini (bukan satu query per transaksi)
+	if err := attachTransactionDetails(transactions); err != nil {
+		log.Println("Detail query error:", err) // Cek log ini jika masih error
+	}
 
-		transactions = append(transactions, t)
+	if paginated {
+		setPageHeaders(w, newPageMeta(page, limit, total))
+	} else {
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(transactions)))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(transactions)
 }
 
-// 3. UPDATE STATUS (Untuk Admin: Proses/Kirim/Selesai/Batal)
+// Ambil detail buku dari satu transaksi
+func getTransactionDetails(transactionID int) ([]models.TransactionDetail, error) {
+	detailsByID, err := getTransactionDetailsByIDs([]int{transactionID})
+	if err != nil {
+		return nil, err
+	}
+	return detailsByID[transactionID], nil
+}
+
+// attachTransactionDetails mengisi Details setiap transaksi di slice dengan satu query batch.
+func attachTransactionDetails(transactions []models.Transaction) error {
+	ids := make([]int, len(transactions))
+	for i, t := range transactions {
+		ids[i] = t.ID
+	}
+
+	detailsByID, err := getTransactionDetailsByIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range transactions {
+		transactions[i].Details = detailsByID[transactions[i].ID]
+	}
+	return nil
+}
+
+// getTransactionDetailsByIDs mengambil detail buku banyak transaksi sekaligus
+// (WHERE transaction_id IN (...)), dikelompokkan per transaction_id.
+func getTransactionDetailsByIDs(transactionIDs []int) (map[int][]models.TransactionDetail, error) {
+	detailsByID := map[int][]models.TransactionDetail{}
+	if len(transactionIDs) == 0 {
+		return detailsByID, nil
+	}
+
+	placeholders, args := inClause(transactionIDs)
+
+	// Data buku diambil dari snapshot saat checkout, bukan JOIN ke tabel books
+	detailRows, err := config.DB.Query(`
+		SELECT td.id, td.transaction_id, td.book_id, td.quantity, td.price_at_purchase,
+		       IFNULL(td.book_title, ''), IFNULL(td.book_author, ''), IFNULL(td.book_image, '')
+		FROM transaction_details td
+		WHERE td.transaction_id IN (`+placeholders+`)
+		ORDER BY td.transaction_id, td.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer detailRows.Close()
+
+	for detailRows.Next() {
+		var d models.TransactionDetail
+
+		// Scan detail
+		err := detailRows.Scan(&d.ID, &d.TransactionID, &d.BookID, &d.Quantity, &d.Price, &d.Book.Title, &d.Book.Author, &d.Book.Image)
+		if err != nil {
+			log.Println("Scan detail error:", err)
+			continue
+		}
+
+		detailsByID[d.TransactionID] = append(detailsByID[d.TransactionID], d)
+	}
+	return detailsByID, detailRows.Err()
+}
+
+// 3. DETAIL & UPDATE STATUS (Untuk Admin)
+// GET /api/transactions/{id}        -> detail lengkap pesanan
+// PUT /api/transactions/{id}/status -> ubah status (Proses/Kirim/Selesai/Batal)
 func TransactionStatusHandler(w http.ResponseWriter, r *http.Request) {
 	enableCors(&w)
 	if r.Method == "OPTIONS" {
 		return
 	}
 
-	// Ambil ID dari URL (/api/transactions/{id}/status)
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) < 4 {
-		http.Error(w, "Invalid URL", http.StatusBadRequest)
+	id, action, ok := parseIDPath(r.URL.Path, "/api/transactions/")
+	if !ok {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
 		return
 	}
-	idStr := parts[3] // index ke-3 adalah ID
-	id, _ := strconv.Atoi(idStr)
 
-	if r.Method != "PUT" {
+	switch {
+	case action == "" && r.Method == "GET":
+		getTransaction(w, id)
+	case action == "status" && r.Method == "PUT":
+		updateTransactionStatus(w, r, id)
+	case action != "" && action != "status":
+		http.NotFound(w, r)
+	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Detail satu pesanan: data customer, item, dan riwayat status
+func getTransaction(w http.ResponseWriter, id int) {
+	var t models.Transaction
+	var customerID sql.NullInt64
+	var email, phone, address sql.NullString
+
+	row := config.DB.QueryRow(`
+		SELECT id, order_code, customer_id, customer_name, customer_email, customer_phone, customer_address,
+		       total_amount, status, payment_method, created_at
+		FROM transactions
+		WHERE id = ?`, id)
+
+	err := row.Scan(&t.ID, &t.OrderCode, &customerID, &t.CustomerName, &email, &phone, &address,
+		&t.TotalAmount, &t.Status, &t.PaymentMethod, &t.Date)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Pesanan tidak ditemukan", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Struct kecil untuk menampung JSON input { "status": 101 }
-	type StatusReq struct {
-		Status int `json:"status"`
+	if customerID.Valid {
+		cid := int(customerID.Int64)
+		t.CustomerID = &cid
+	}
+	t.CustomerEmail = email.String
+	t.CustomerPhone = phone.String
+	t.Address = address.String
+	t.StatusName = models.StatusName(t.Status)
+
+	details, err := getTransactionDetails(t.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-	var req StatusReq
+	t.Details = details
+
+	history, err := getStatusHistory(t.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	t.History = history
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+func updateTransactionStatus(w http.ResponseWriter, r *http.Request, id int) {
+	// JSON input { "status": 101 } atau { "status_name": "paid" }
+	var req models.StatusUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	_, err := config.DB.Exec("UPDATE transactions SET status = ? WHERE id = ?", req.Status, id)
+	if req.StatusName != "" {
+		status, ok := models.StatusFromName(req.StatusName)
+		if !ok {
+			http.Error(w, "Status tidak dikenal", http.StatusBadRequest)
+			return
+		}
+		req.Status = status
+	}
+	if !models.ValidStatus(req.Status) {
+		http.Error(w, "Status tidak dikenal", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := config.DB.Begin()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	actorID := currentUserID(r)
+	from, err := changeTransactionStatus(tx, id, req.Status, &actorID, strings.TrimSpace(req.Note))
+	if err != nil {
+		tx.Rollback()
+		switch err {
+		case errTransactionNotFound:
+			http.Error(w, "Pesanan tidak ditemukan", http.StatusNotFound)
+		case errIllegalTransition:
+			allowed := []string{}
+			for _, next := range models.AllowedTransitions(from) {
+				allowed = append(allowed, models.StatusName(next))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"message": "Status tidak bisa diubah dari " + models.StatusName(from) + " ke " + models.StatusName(req.Status),
+				"from":    models.StatusName(from),
+				"to":      models.StatusName(req.Status),
+				"allowed": allowed,
+			})
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "Status updated successfully"})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     "Status updated successfully",
+		"status":      req.Status,
+		"status_name": models.StatusName(req.Status),
+	})
 }
 
 func GetTransactionByCodeHandler(w http.ResponseWriter, r *http.Request) {
@@ -253,12 +702,18 @@ func GetTransactionByCodeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Ambil code dari URL query param
-	code := r.URL.Query().Get("code")
+	code := utils.NormalizeOrderCode(r.URL.Query().Get("code"))
 	if code == "" {
 		http.Error(w, "Order Code is required", http.StatusBadRequest)
 		return
 	}
 
+	// Cek check digit dulu agar salah ketik langsung ketahuan
+	if utils.IsNewOrderCodeFormat(code) && !utils.ValidOrderCode(code) {
+		http.Error(w, "Order Code tidak valid, periksa kembali penulisannya", http.StatusBadRequest)
+		return
+	}
+
 	// Variable penampung
 	var t models.Transaction
 
@@ -287,32 +742,25 @@ func GetTransactionByCodeHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	t.StatusName = models.StatusName(t.Status)
 
 	// 2. Query Detail Barang (Agar user tahu beli apa)
-	// Kita gunakan 'price_at_purchase' karena nama kolomnya itu
-	rows, err := config.DB.Query(`
-		SELECT td.quantity, td.price_at_purchase, b.title, b.image_url 
-		FROM transaction_details td 
-		JOIN books b ON td.book_id = b.id 
-		WHERE td.transaction_id = ?`, t.ID)
-
-	if err == nil {
-		defer rows.Close()
-
-		var details []models.TransactionDetail
-		for rows.Next() {
-			var d models.TransactionDetail
-
-			// Scan data detail
-			err := rows.Scan(&d.Quantity, &d.Price, &d.Book.Title, &d.Book.Image)
-			if err != nil {
-				continue
-			}
+	details, err := getTransactionDetails(t.ID)
+	if err != nil {
+		log.Println("Detail query error:", err)
+	}
+	t.Details = details
 
-			details = append(details, d)
-		}
-		t.Details = details
+	// 3. Timeline status (tracking). Siapa yang mengubah tidak ditampilkan ke publik.
+	history, err := getStatusHistory(t.ID)
+	if err != nil {
+		log.Println("History query error:", err)
+	}
+	for i := range history {
+		history[i].ChangedBy = nil
+		history[i].ChangedByName = ""
 	}
+	t.History = history
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(t)
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"be/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Satu halaman daftar transaksi = 3 query (COUNT, transaksi, detail), berapa pun isi halamannya.
+// sqlmock menolak query tambahan, jadi pola N+1 (satu query detail per transaksi) membuat test gagal.
+func TestTransactionListLoadsDetailsInOneQuery(t *testing.T) {
+	mock := mockDB(t)
+	date := time.Date(2025, 1, 30, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(6))
+
+	transactions := sqlmock.NewRows([]string{
+		"id", "order_code", "customer_name", "customer_email", "customer_phone", "customer_address",
+		"total_amount", "status", "payment_method", "created_at",
+	})
+	for _, id := range []int{3, 2, 1} {
+		transactions.AddRow(id, "B3-TEST", "Budi", "budi@example.com", "0812", "Jl. Mawar", 50000, models.StatusPendingPayment, "transfer", date)
+	}
+	mock.ExpectQuery("FROM transactions").WithArgs(3, 3).WillReturnRows(transactions)
+
+	mock.ExpectQuery("FROM transaction_details td WHERE td.transaction_id IN \\(\\?,\\?,\\?\\)").
+		WithArgs(3, 2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "transaction_id", "book_id", "quantity", "price_at_purchase", "book_title", "book_author", "book_image",
+		}).
+			AddRow(1, 1, 10, 1, 25000, "Laskar Pelangi", "Andrea Hirata", "").
+			AddRow(2, 1, 11, 1, 25000, "Bumi", "Tere Liye", "").
+			AddRow(3, 3, 12, 2, 25000, "Negeri 5 Menara", "Ahmad Fuadi", ""))
+
+	rec := httptest.NewRecorder()
+	TransactionListHandler(rec, httptest.NewRequest("GET", "/api/transactions?page=2&limit=3", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	// Response tetap array biasa, info pagination di header
+	var resp []models.Transaction
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Header().Get("X-Total-Count"); got != "6" {
+		t.Errorf("X-Total-Count = %q, want 6", got)
+	}
+	if got := rec.Header().Get("X-Total-Pages"); got != "2" {
+		t.Errorf("X-Total-Pages = %q, want 2", got)
+	}
+
+	// Detail harus masuk ke transaksinya masing-masing
+	want := map[int]int{3: 1, 2: 0, 1: 2}
+	if len(resp) != len(want) {
+		t.Fatalf("got %d transactions, want %d", len(resp), len(want))
+	}
+	for _, tr := range resp {
+		if len(tr.Details) != want[tr.ID] {
+			t.Errorf("transaction %d: got %d details, want %d", tr.ID, len(tr.Details), want[tr.ID])
+		}
+		for _, d := range tr.Details {
+			if d.TransactionID != tr.ID {
+				t.Errorf("transaction %d got detail of transaction %d", tr.ID, d.TransactionID)
+			}
+		}
+	}
+}
+
+// Tanpa ?page= / ?limit= dashboard lama tetap mendapat semua transaksi sebagai array biasa.
+func TestTransactionListKeepsBareArray(t *testing.T) {
+	mock := mockDB(t)
+	mock.ExpectQuery("FROM transactions\\s+ORDER BY created_at DESC, id DESC\\s*$").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	rec := httptest.NewRecorder()
+	TransactionListHandler(rec, httptest.NewRequest("GET", "/api/transactions", nil))
+
+	if body := strings.TrimSpace(rec.Body.String()); body != "[]" {
+		t.Errorf("body = %s, want []", body)
+	}
+	if got := rec.Header().Get("X-Total-Count"); got != "0" {
+		t.Errorf("X-Total-Count = %q, want 0", got)
+	}
+}
@@ -2,15 +2,54 @@ package controllers
 
 import (
 	"be/config"
+	"be/middleware"
 	"be/models"
+	"be/utils"
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Pembatas percobaan login: per akun (5x gagal) dan per IP (20x gagal),
+// dikunci mulai 30 detik lalu naik 2x lipat setiap gagal lagi, maksimal 15 menit.
+var (
+	accountGuard = utils.NewLoginGuard(5, 30*time.Second, 15*time.Minute)
+	ipGuard      = utils.NewLoginGuard(20, 30*time.Second, 15*time.Minute)
+)
+
+// Ambil IP client (tanpa port)
+func clientIP(r *http.Request) string {
+	if config.TrustProxy {
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Kirim 429 Too Many Requests beserta header Retry-After (detik)
+func tooManyAttempts(w http.ResponseWriter, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	http.Error(w, fmt.Sprintf("Terlalu banyak percobaan login, coba lagi dalam %d detik", seconds), http.StatusTooManyRequests)
+}
+
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	enableCors(&w)
-	if r.Method == "OPTIONS" { return }
+	if r.Method == "OPTIONS" {
+		return
+	}
 
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -23,30 +62,417 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Cek apakah IP / akun sedang dikunci karena terlalu banyak gagal.
+	// Percobaan ini langsung dihitung gagal sebelum password dicek (atomik di LoginGuard),
+	// agar request paralel tidak bisa melewati batas selama bcrypt berjalan.
+	ip := clientIP(r)
+	accountKey := strings.ToLower(strings.TrimSpace(req.Username))
+	ipWait, ipLock := ipGuard.Attempt(ip)
+	if ipWait > 0 {
+		tooManyAttempts(w, ipWait)
+		return
+	}
+	accountWait, accountLock := accountGuard.Attempt(accountKey)
+	if accountWait > 0 {
+		ipGuard.Cancel(ip)
+		tooManyAttempts(w, accountWait)
+		return
+	}
+
+	// Kegagalan sudah tercatat; jika percobaan ini membuat akun/IP terkunci, langsung balas 429
+	loginFailed := func() {
+		if wait := max(ipLock, accountLock); wait > 0 {
+			tooManyAttempts(w, wait)
+			return
+		}
+		http.Error(w, "Username atau Password salah", http.StatusUnauthorized)
+	}
+
 	var user models.User
-	// Query cek user (Password masih plain text untuk pembelajaran)
-	// Di production WAJIB pakai hashing (bcrypt)
-	row := config.DB.QueryRow("SELECT id, username, role FROM users WHERE username=? AND password=?", req.Username, req.Password)
-	
-	err := row.Scan(&user.ID, &user.Username, &user.Role)
+	// Password dicek di Go (bcrypt), bukan di WHERE clause
+	row := config.DB.QueryRow("SELECT id, username, password, role, is_disabled FROM users WHERE username=?", req.Username)
+
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Role, &user.Disabled)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			http.Error(w, "Username atau Password salah", http.StatusUnauthorized)
+			utils.DummyCheckPassword(req.Password)
+			loginFailed()
 			return
 		}
+		// Error server bukan salah password, jangan dihitung
+		ipGuard.Cancel(ip)
+		accountGuard.Cancel(accountKey)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	ok, needsRehash := utils.CheckPassword(user.Password, req.Password)
+	if !ok {
+		loginFailed()
+		return
+	}
+
+	// Password benar -> counter akun di-reset, dan percobaan ini dibatalkan dari counter IP
+	// (counter IP tidak di-reset, agar tidak bisa di-reset pakai akun sendiri)
+	accountGuard.Reset(accountKey)
+	ipGuard.Cancel(ip)
+
+	// Akun yang dinonaktifkan admin tidak boleh login
+	if user.Disabled {
+		http.Error(w, "Akun dinonaktifkan, hubungi admin", http.StatusForbidden)
+		return
+	}
+
+	// Migrasi bertahap: password lama (plain text) langsung di-hash saat login sukses
+	if needsRehash {
+		if hash, err := utils.HashPassword(req.Password); err == nil {
+			if _, err := config.DB.Exec("UPDATE users SET password=? WHERE id=?", hash, user.ID); err != nil {
+				log.Println("Gagal upgrade hash password:", err)
+			}
+		}
+	}
+
 	// Login Sukses
+	sendLoginResponse(w, http.StatusOK, user, "Login Berhasil")
+}
+
+// Buat sesi baru (refresh token) + access token (JWT, ditandatangani HMAC) lalu kirim sebagai LoginResponse
+func sendLoginResponse(w http.ResponseWriter, status int, user models.User, message string) {
+	sessionID, refreshToken, refreshExpiresAt, err := createSession(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	token, expiresAt, err := utils.GenerateToken(user, sessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	resp := models.LoginResponse{
-		Status:  true,
-		Message: "Login Berhasil",
-		Token:   "dummy-token-secret-123", // Di real app gunakan JWT Library
-		Role:    user.Role,
-		Username: user.Username,
+		Status:           true,
+		Message:          message,
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+		Role:             user.Role,
+		Username:         user.Username,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(resp)
-}
\ No newline at end of file
+}
+
+// --- USER MANAGEMENT (Khusus Admin) ---
+
+// 1. LIST & CREATE (URL: /api/users)
+func UsersHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		getUsers(w)
+	case "POST":
+		createUser(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// 2. DETAIL, UPDATE, DELETE, RESET PASSWORD, REVOKE SESI, UNLOCK LOGIN
+// (URL: /api/users/{id}, /api/users/{id}/password, /api/users/{id}/sessions, /api/users/{id}/unlock)
+func UserDetailHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	id, action, ok := parseIDPath(r.URL.Path, "/api/users/")
+	if !ok {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == "GET":
+		getUser(w, id)
+	case action == "" && r.Method == "PUT":
+		updateUser(w, r, id)
+	case action == "" && r.Method == "DELETE":
+		deleteUser(w, r, id)
+	case action == "password" && r.Method == "PUT":
+		resetUserPassword(w, r, id)
+	case action == "sessions" && r.Method == "DELETE":
+		revokeSessionsHandler(w, id)
+	case action == "unlock" && r.Method == "POST":
+		unlockUser(w, id)
+	case action != "" && action != "password" && action != "sessions" && action != "unlock":
+		http.NotFound(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func validRole(role string) bool {
+	return role == models.RoleAdmin || role == models.RoleCustomer
+}
+
+// Password minimal 8 karakter
+func validPassword(password string) bool {
+	return len(password) >= 8
+}
+
+// Admin yang sedang login (diisi oleh middleware.Authorize)
+func currentUserID(r *http.Request) int {
+	if claims, ok := middleware.UserFromContext(r.Context()); ok {
+		return claims.UserID
+	}
+	return 0
+}
+
+// Cek apakah request berasal dari admin yang sedang login
+func isAdmin(r *http.Request) bool {
+	claims, ok := middleware.UserFromContext(r.Context())
+	return ok && claims.Role == models.RoleAdmin
+}
+
+func getUsers(w http.ResponseWriter) {
+	rows, err := config.DB.Query("SELECT id, username, role, is_disabled, created_at FROM users ORDER BY id ASC")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.Role, &user.Disabled, &user.CreatedAt); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		users = append(users, user)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+func getUser(w http.ResponseWriter, id int) {
+	var user models.User
+	row := config.DB.QueryRow("SELECT id, username, role, is_disabled, created_at FROM users WHERE id = ?", id)
+
+	err := row.Scan(&user.ID, &user.Username, &user.Role, &user.Disabled, &user.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+func createUser(w http.ResponseWriter, r *http.Request) {
+	var user models.User
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user.Username = strings.TrimSpace(user.Username)
+	if user.Username == "" {
+		http.Error(w, "Username wajib diisi", http.StatusBadRequest)
+		return
+	}
+	if !validPassword(user.Password) {
+		http.Error(w, "Password minimal 8 karakter", http.StatusBadRequest)
+		return
+	}
+	if !validRole(user.Role) {
+		http.Error(w, "Role tidak dikenal", http.StatusBadRequest)
+		return
+	}
+
+	// Username harus unik
+	var exists int
+	config.DB.QueryRow("SELECT COUNT(*) FROM users WHERE username = ?", user.Username).Scan(&exists)
+	if exists > 0 {
+		http.Error(w, "Username sudah dipakai", http.StatusConflict)
+		return
+	}
+
+	hash, err := utils.HashPassword(user.Password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := config.DB.Exec("INSERT INTO users (username, password, role, is_disabled) VALUES (?, ?, ?, ?)",
+		user.Username, hash, user.Role, user.Disabled)
+	if err != nil {
+		if utils.IsDuplicateEntry(err) {
+			http.Error(w, "Username sudah dipakai", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	user.ID = int(id)
+	user.Password = "" // Jangan kirim balik password
+	user.CreatedAt = time.Now()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+func updateUser(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Role != nil && !validRole(*req.Role) {
+		http.Error(w, "Role tidak dikenal", http.StatusBadRequest)
+		return
+	}
+
+	// Admin tidak boleh menonaktifkan / menurunkan role akunnya sendiri (agar tidak terkunci)
+	if id == currentUserID(r) {
+		if (req.Disabled != nil && *req.Disabled) || (req.Role != nil && *req.Role != models.RoleAdmin) {
+			http.Error(w, "Tidak bisa menonaktifkan atau mengubah role akun sendiri", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var user models.User
+	row := config.DB.QueryRow("SELECT id, username, role, is_disabled, created_at FROM users WHERE id = ?", id)
+	if err := row.Scan(&user.ID, &user.Username, &user.Role, &user.Disabled, &user.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	oldRole := user.Role
+	if req.Role != nil {
+		user.Role = *req.Role
+	}
+	if req.Disabled != nil {
+		user.Disabled = *req.Disabled
+	}
+
+	_, err := config.DB.Exec("UPDATE users SET role=?, is_disabled=? WHERE id=?", user.Role, user.Disabled, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// User yang dinonaktifkan atau rolenya berubah langsung dikeluarkan dari semua sesi,
+	// karena access token lama masih membawa role lama sampai kedaluwarsa
+	if user.Disabled || user.Role != oldRole {
+		if err := revokeUserSessions(id); err != nil {
+			log.Println("Gagal revoke sesi user:", err)
+			http.Error(w, "User diperbarui, tapi gagal mencabut sesi: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+func resetUserPassword(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !validPassword(req.Password) {
+		http.Error(w, "Password minimal 8 karakter", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := config.DB.Exec("UPDATE users SET password=? WHERE id=?", hash, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	// Password diganti -> sesi lama tidak berlaku lagi
+	if err := revokeUserSessions(id); err != nil {
+		log.Println("Gagal revoke sesi user:", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password updated successfully"})
+}
+
+func deleteUser(w http.ResponseWriter, r *http.Request, id int) {
+	if id == currentUserID(r) {
+		http.Error(w, "Tidak bisa menghapus akun sendiri", http.StatusBadRequest)
+		return
+	}
+
+	result, err := config.DB.Exec("DELETE FROM users WHERE id=?", id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	if err := revokeUserSessions(id); err != nil {
+		log.Println("Gagal revoke sesi user:", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "User deleted successfully"})
+}
+
+// Buka kunci login akun yang terkunci karena terlalu banyak gagal
+func unlockUser(w http.ResponseWriter, id int) {
+	var username string
+	err := config.DB.QueryRow("SELECT username FROM users WHERE id = ?", id).Scan(&username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	accountGuard.Reset(strings.ToLower(username))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "User unlocked successfully"})
+}
@@ -3,17 +3,54 @@ package main
 import (
 	"be/config"
 	"be/controllers"
+	"be/middleware"
+	"be/notifier"
+	"context"
 	"fmt"
 	"net/http"
 )
 
 func main() {
+	// Wajib diisi lewat environment variable JWT_SECRET (server berhenti jika kosong)
+	config.LoadJWTSecret()
+
 	config.ConnectDB()
+	config.Migrate()
+
+	// Development: link reset password ditulis ke log server.
+	// Ganti dengan implementasi email/WhatsApp di production.
+	controllers.Notifier = notifier.NewLogNotifier("")
+
+	// Index pencarian buku (in-memory)
+	if err := controllers.BuildSearchIndex(); err != nil {
+		panic(err)
+	}
+
+	// Worker background: batalkan pesanan yang tidak dibayar melewati batas waktu
+	controllers.NewOrderExpiryWorker(config.OrderExpiryWindow, config.OrderExpiryInterval).Start(context.Background())
 
 	// --- ROUTING API ---
+	// Hak akses (role) setiap route diatur di middleware/policy.go
 	http.HandleFunc("/api/books", controllers.BooksHandler)
 	http.HandleFunc("/api/books/", controllers.BookDetailHandler)
+	http.HandleFunc("/api/books/search", controllers.BookSearchHandler)
+	http.HandleFunc("/api/books/suggest", controllers.BookSuggestHandler)
+	http.HandleFunc("/api/categories", controllers.CategoriesHandler)
+	http.HandleFunc("/api/categories/", controllers.CategoryDetailHandler)
+	http.HandleFunc("/api/authors", controllers.AuthorsHandler)
+	http.HandleFunc("/api/authors/", controllers.AuthorDetailHandler)
 	http.HandleFunc("/api/login", controllers.LoginHandler)
+	http.HandleFunc("/api/refresh", controllers.RefreshHandler)
+	http.HandleFunc("/api/logout", controllers.LogoutHandler)
+	http.HandleFunc("/api/password/forgot", controllers.ForgotPasswordHandler)
+	http.HandleFunc("/api/password/reset", controllers.ResetPasswordHandler)
+	http.HandleFunc("/api/register", controllers.RegisterHandler)
+	http.HandleFunc("/api/me", controllers.ProfileHandler)
+	http.HandleFunc("/api/me/addresses", controllers.AddressesHandler)
+	http.HandleFunc("/api/me/addresses/", controllers.AddressDetailHandler)
+	http.HandleFunc("/api/me/orders", controllers.MyOrdersHandler)
+	http.HandleFunc("/api/users", controllers.UsersHandler)
+	http.HandleFunc("/api/users/", controllers.UserDetailHandler)
 	http.HandleFunc("/api/checkout", controllers.CheckoutHandler)
 	http.HandleFunc("/api/transactions", controllers.TransactionListHandler)
 	http.HandleFunc("/api/transactions/", controllers.TransactionStatusHandler)
@@ -26,7 +63,7 @@ func main() {
 
 	port := ":8082"
 	fmt.Println("Server running on port", port)
-	err := http.ListenAndServe(port, nil)
+	err := http.ListenAndServe(port, middleware.Authorize(http.DefaultServeMux))
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"be/utils"
+	"context"
+	"net/http"
+	"strings"
+)
+
+type contextKey string
+
+const claimsKey contextKey = "claims"
+
+// Header CORS juga dipasang di sini, karena request yang ditolak
+// tidak pernah sampai ke handler (yang biasanya memasang CORS).
+func enableCors(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+}
+
+// Ambil token dari header "Authorization: Bearer <token>"
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// authenticate memvalidasi access token pada request.
+// Mengembalikan pesan error (untuk response 401) jika token tidak ada / tidak valid.
+func authenticate(r *http.Request) (*utils.Claims, string) {
+	tokenStr := bearerToken(r)
+	if tokenStr == "" {
+		return nil, "Unauthorized: token tidak ditemukan"
+	}
+
+	claims, err := utils.ParseToken(tokenStr)
+	if err != nil {
+		return nil, "Unauthorized: token tidak valid atau sudah kedaluwarsa"
+	}
+	return claims, ""
+}
+
+// UserFromContext mengambil data user (hasil validasi token) dari context request.
+func UserFromContext(ctx context.Context) (*utils.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*utils.Claims)
+	return claims, ok
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"be/models"
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Rule menentukan role apa saja yang boleh mengakses sebuah route.
+// Path mengikuti pola http.HandleFunc: akhiran "/" berarti prefix (misal "/api/books/").
+// Methods kosong berarti berlaku untuk semua method.
+type Rule struct {
+	Path    string
+	Methods []string
+	Roles   []string
+}
+
+// Daftar hak akses setiap route di main.go.
+// Route yang tidak ada di sini dianggap publik.
+var Policies = []Rule{
+	{Path: "/api/books", Methods: []string{"POST"}, Roles: []string{models.RoleAdmin}},
+	{Path: "/api/books/", Methods: []string{"POST", "PUT", "DELETE"}, Roles: []string{models.RoleAdmin}},
+	{Path: "/api/categories", Methods: []string{"POST"}, Roles: []string{models.RoleAdmin}},
+	{Path: "/api/categories/", Methods: []string{"PUT", "DELETE"}, Roles: []string{models.RoleAdmin}},
+	{Path: "/api/authors", Methods: []string{"POST"}, Roles: []string{models.RoleAdmin}},
+	{Path: "/api/authors/", Methods: []string{"PUT", "DELETE"}, Roles: []string{models.RoleAdmin}},
+	{Path: "/api/transactions", Roles: []string{models.RoleAdmin}},
+	{Path: "/api/transactions/", Roles: []string{models.RoleAdmin}},
+	{Path: "/api/upload", Roles: []string{models.RoleAdmin}},
+	{Path: "/api/users", Roles: []string{models.RoleAdmin}},
+	{Path: "/api/users/", Roles: []string{models.RoleAdmin}},
+	{Path: "/api/me", Roles: []string{models.RoleCustomer}},
+	{Path: "/api/me/", Roles: []string{models.RoleCustomer}},
+}
+
+// RequiredRoles mencari rule yang cocok untuk path & method.
+// Jika beberapa rule cocok, yang dipakai adalah path terpanjang (paling spesifik),
+// sama seperti cara http.ServeMux memilih handler.
+func RequiredRoles(path, method string) ([]string, bool) {
+	var matched *Rule
+	for i := range Policies {
+		rule := &Policies[i]
+		if !pathMatches(rule.Path, path) || !methodMatches(rule.Methods, method) {
+			continue
+		}
+		if matched == nil || len(rule.Path) > len(matched.Path) {
+			matched = rule
+		}
+	}
+
+	if matched == nil {
+		return nil, false
+	}
+	return matched.Roles, true
+}
+
+func pathMatches(pattern, path string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(path, pattern)
+	}
+	return path == pattern
+}
+
+func methodMatches(methods []string, method string) bool {
+	if len(methods) == 0 {
+		return true
+	}
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize membungkus router: cek token (401) lalu cek role (403)
+// berdasarkan tabel Policies sebelum request diteruskan ke handler.
+func Authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Preflight CORS tidak membawa Authorization header
+		if r.Method == "OPTIONS" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		roles, protected := RequiredRoles(r.URL.Path, r.Method)
+		if !protected {
+			// Route publik: jika kebetulan membawa token valid (misal checkout oleh customer
+			// yang sedang login), tetap simpan datanya di context. Token invalid diabaikan.
+			if claims, _ := authenticate(r); claims != nil && sessionActive(claims.SessionID, claims.UserID) {
+				r = r.WithContext(context.WithValue(r.Context(), claimsKey, claims))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		claims, errMsg := authenticate(r)
+		if claims == nil {
+			enableCors(w)
+			http.Error(w, errMsg, http.StatusUnauthorized)
+			return
+		}
+
+		if !sessionActive(claims.SessionID, claims.UserID) {
+			enableCors(w)
+			http.Error(w, "Unauthorized: sesi sudah berakhir, silakan login ulang", http.StatusUnauthorized)
+			return
+		}
+
+		if len(roles) > 0 && !hasRole(roles, claims.Role) {
+			enableCors(w)
+			http.Error(w, "Forbidden: role tidak memiliki akses", http.StatusForbidden)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), claimsKey, claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
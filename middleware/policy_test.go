@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"be/config"
+	"be/models"
+	"be/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Hasil yang diharapkan untuk setiap role
+const (
+	pass         = http.StatusOK // Request sampai ke handler
+	unauthorized = http.StatusUnauthorized
+	forbidden    = http.StatusForbidden
+)
+
+// Semua route di main.go beserta hasil untuk tamu (tanpa token), customer, dan admin
+var policyCases = []struct {
+	method, path               string
+	anonymous, customer, admin int
+}{
+	// Katalog buku
+	{"GET", "/api/books", pass, pass, pass},
+	{"POST", "/api/books", unauthorized, forbidden, pass},
+	{"GET", "/api/books/5", pass, pass, pass},
+	{"PUT", "/api/books/5", unauthorized, forbidden, pass},
+	{"DELETE", "/api/books/5", unauthorized, forbidden, pass},
+	{"POST", "/api/books/5/restore", unauthorized, forbidden, pass},
+	{"GET", "/api/books/search?q=laskar", pass, pass, pass},
+	{"GET", "/api/books/suggest?q=las", pass, pass, pass},
+
+	// Kategori & penulis
+	{"GET", "/api/categories", pass, pass, pass},
+	{"POST", "/api/categories", unauthorized, forbidden, pass},
+	{"GET", "/api/categories/3", pass, pass, pass},
+	{"PUT", "/api/categories/3", unauthorized, forbidden, pass},
+	{"DELETE", "/api/categories/3", unauthorized, forbidden, pass},
+	{"GET", "/api/authors", pass, pass, pass},
+	{"POST", "/api/authors", unauthorized, forbidden, pass},
+	{"GET", "/api/authors/7", pass, pass, pass},
+	{"GET", "/api/authors/7/books", pass, pass, pass},
+	{"PUT", "/api/authors/7", unauthorized, forbidden, pass},
+	{"DELETE", "/api/authors/7", unauthorized, forbidden, pass},
+
+	// Auth & akun
+	{"POST", "/api/login", pass, pass, pass},
+	{"POST", "/api/refresh", pass, pass, pass},
+	{"POST", "/api/logout", pass, pass, pass},
+	{"POST", "/api/password/forgot", pass, pass, pass},
+	{"POST", "/api/password/reset", pass, pass, pass},
+	{"POST", "/api/register", pass, pass, pass},
+
+	// Area customer
+	{"GET", "/api/me", unauthorized, pass, forbidden},
+	{"PUT", "/api/me", unauthorized, pass, forbidden},
+	{"GET", "/api/me/addresses", unauthorized, pass, forbidden},
+	{"POST", "/api/me/addresses", unauthorized, pass, forbidden},
+	{"PUT", "/api/me/addresses/2", unauthorized, pass, forbidden},
+	{"DELETE", "/api/me/addresses/2", unauthorized, pass, forbidden},
+	{"GET", "/api/me/orders", unauthorized, pass, forbidden},
+
+	// Manajemen user (admin)
+	{"GET", "/api/users", unauthorized, forbidden, pass},
+	{"POST", "/api/users", unauthorized, forbidden, pass},
+	{"GET", "/api/users/4", unauthorized, forbidden, pass},
+	{"PUT", "/api/users/4", unauthorized, forbidden, pass},
+	{"DELETE", "/api/users/4", unauthorized, forbidden, pass},
+	{"PUT", "/api/users/4/password", unauthorized, forbidden, pass},
+	{"DELETE", "/api/users/4/sessions", unauthorized, forbidden, pass},
+	{"POST", "/api/users/4/unlock", unauthorized, forbidden, pass},
+
+	// Transaksi
+	{"POST", "/api/checkout", pass, pass, pass},
+	{"GET", "/api/transactions", unauthorized, forbidden, pass},
+	{"GET", "/api/transactions/9", unauthorized, forbidden, pass},
+	{"PUT", "/api/transactions/9/status", unauthorized, forbidden, pass},
+	{"GET", "/api/check-order?code=B3-20250130-K7M3-QX9A", pass, pass, pass},
+
+	// Upload & file statis
+	{"POST", "/api/upload", unauthorized, forbidden, pass},
+	{"GET", "/uploads/1723123-buku.jpg", pass, pass, pass},
+
+	// Preflight CORS selalu diteruskan (tidak membawa token)
+	{"OPTIONS", "/api/books/5", pass, pass, pass},
+	{"OPTIONS", "/api/users", pass, pass, pass},
+	{"OPTIONS", "/api/me/addresses/2", pass, pass, pass},
+	{"OPTIONS", "/api/upload", pass, pass, pass},
+}
+
+func setupPolicyTest(t *testing.T) sqlmock.Sqlmock {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldDB, oldSecret := config.DB, config.JWTSecret
+	config.DB = db
+	config.JWTSecret = []byte("policy-test-secret-0123456789abcdef")
+	t.Cleanup(func() {
+		db.Close()
+		config.DB, config.JWTSecret = oldDB, oldSecret
+	})
+	return mock
+}
+
+func tokenFor(t *testing.T, role string) string {
+	t.Helper()
+
+	token, _, err := utils.GenerateToken(models.User{ID: 1, Role: role}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// expectSession menyiapkan hasil query sessionActive (active = sesi belum dicabut)
+func expectSession(mock sqlmock.Sqlmock, active bool) {
+	count := 0
+	if active {
+		count = 1
+	}
+	mock.ExpectQuery("FROM refresh_tokens").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
+func serve(method, path, token string) int {
+	handler := Authorize(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestAuthorizePolicyTable(t *testing.T) {
+	mock := setupPolicyTest(t)
+
+	roles := []struct {
+		name  string
+		token string
+		want  func(i int) int
+	}{
+		{"anonymous", "", func(i int) int { return policyCases[i].anonymous }},
+		{models.RoleCustomer, tokenFor(t, models.RoleCustomer), func(i int) int { return policyCases[i].customer }},
+		{models.RoleAdmin, tokenFor(t, models.RoleAdmin), func(i int) int { return policyCases[i].admin }},
+	}
+
+	for i, tc := range policyCases {
+		for _, role := range roles {
+			t.Run(tc.method+" "+tc.path+" as "+role.name, func(t *testing.T) {
+				// Setiap request bertoken (kecuali preflight) mengecek sesi ke DB
+				if role.token != "" && tc.method != "OPTIONS" {
+					expectSession(mock, true)
+				}
+
+				if got, want := serve(tc.method, tc.path, role.token), role.want(i); got != want {
+					t.Errorf("status = %d, want %d", got, want)
+				}
+				if err := mock.ExpectationsWereMet(); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+func TestAuthorizeRejectsInvalidOrRevokedToken(t *testing.T) {
+	mock := setupPolicyTest(t)
+	admin := tokenFor(t, models.RoleAdmin)
+
+	if got := serve("GET", "/api/users", "bukan-jwt"); got != unauthorized {
+		t.Errorf("invalid token: status = %d, want %d", got, unauthorized)
+	}
+
+	// Token ditandatangani secret lain (misal token palsu)
+	config.JWTSecret = []byte("secret-lain-yang-tidak-dipakai-server")
+	forged := tokenFor(t, models.RoleAdmin)
+	config.JWTSecret = []byte("policy-test-secret-0123456789abcdef")
+	if got := serve("GET", "/api/users", forged); got != unauthorized {
+		t.Errorf("forged token: status = %d, want %d", got, unauthorized)
+	}
+
+	// Sesi sudah logout / dicabut admin
+	expectSession(mock, false)
+	if got := serve("GET", "/api/users", admin); got != unauthorized {
+		t.Errorf("revoked session: status = %d, want %d", got, unauthorized)
+	}
+
+	// Di route publik, sesi yang dicabut tidak membuat request gagal
+	expectSession(mock, false)
+	if got := serve("GET", "/api/books", admin); got != pass {
+		t.Errorf("revoked session on public route: status = %d, want %d", got, pass)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRequiredRolesPicksMostSpecificRule(t *testing.T) {
+	tests := []struct {
+		method, path string
+		protected    bool
+	}{
+		{"GET", "/api/books/5", false},
+		{"POST", "/api/books/5/restore", true},
+		{"GET", "/api/me/orders", true},
+		{"GET", "/api/meta", false},
+		{"GET", "/api/usersx", false},
+	}
+
+	for _, tc := range tests {
+		if _, protected := RequiredRoles(tc.path, tc.method); protected != tc.protected {
+			t.Errorf("RequiredRoles(%q, %q) protected = %v, want %v", tc.path, tc.method, protected, tc.protected)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"be/config"
+	"time"
+)
+
+// sessionActive mengecek apakah sesi (refresh token) yang menerbitkan access token
+// belum dicabut, sehingga logout / revoke oleh admin langsung berlaku
+// tanpa menunggu access token kedaluwarsa.
+// Waktu pembanding diambil dari Go (bukan NOW() MySQL), sama seperti saat expires_at ditulis
+// dan saat dicek di RefreshHandler, agar tidak meleset karena beda zona waktu server DB.
+func sessionActive(sessionID, userID int) bool {
+	var count int
+	err := config.DB.QueryRow(`SELECT COUNT(*) FROM refresh_tokens
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?`, sessionID, userID, time.Now()).Scan(&count)
+	return err == nil && count > 0
+}
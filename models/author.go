@@ -0,0 +1,27 @@
+package models
+
+import "strings"
+
+// Penulis buku (Tabel authors). Buku bisa punya banyak penulis lewat tabel book_authors.
+type Author struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Bio       string `json:"bio"`
+	PhotoURL  string `json:"photo"` // Di DB kolomnya photo_url, diupload lewat /api/upload
+	BookCount int    `json:"book_count,omitempty"`
+}
+
+// SplitAuthorNames memecah teks penulis lama ("A, B") menjadi daftar nama.
+func SplitAuthorNames(author string) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, name := range strings.Split(author, ",") {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names
+}
@@ -1,13 +1,38 @@
 package models
 
+import "time"
+
+// Format buku yang diterima
+const (
+	FormatPaperback = "paperback"
+	FormatHardcover = "hardcover"
+	FormatEbook     = "ebook"
+)
+
 // Sesuaikan JSON tag dengan apa yang Frontend kirim/terima
 type Book struct {
-	ID          int     `json:"id"`
-	Title       string  `json:"title"`
-	Author      string  `json:"author"`
-	Price       float64 `json:"price"`
-	Category    string  `json:"category"`
-	Stock       int     `json:"stock"`
-	ImageURL    string  `json:"image"` // Di DB kolomnya image_url, di JSON kita sebut image
-	Description string  `json:"description"`
-}
\ No newline at end of file
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Author      string     `json:"author"` // Nama semua penulis digabung ("A, B"), untuk kompatibilitas
+	Price       float64    `json:"price"`
+	Category    string     `json:"category"` // Nama kategori utama (kategori pertama), untuk kompatibilitas
+	Stock       int        `json:"stock"`
+	ImageURL    string     `json:"image"` // Di DB kolomnya image_url, di JSON kita sebut image
+	Description string     `json:"description"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"` // Terisi jika buku diarsipkan (soft delete)
+
+	// Data bibliografi (semuanya opsional)
+	ISBN            string `json:"isbn"` // Disimpan dalam bentuk ISBN-13 tanpa tanda hubung
+	Publisher       string `json:"publisher"`
+	PublicationYear *int   `json:"publication_year"`
+	PageCount       *int   `json:"page_count"`
+	Language        string `json:"language"`
+	Format          string `json:"format"`       // paperback, hardcover, atau ebook
+	WeightGrams     *int   `json:"weight_grams"` // Berat untuk ongkos kirim
+
+	Categories  []Category `json:"categories"`
+	CategoryIDs []int      `json:"category_ids,omitempty"` // Input saat create/update: ID kategori buku
+
+	Authors   []Author `json:"authors"`
+	AuthorIDs []int    `json:"author_ids,omitempty"` // Input saat create/update: ID penulis buku (urutan = urutan di sampul)
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Kategori buku (Tabel categories). Buku bisa punya banyak kategori lewat tabel book_categories.
+type Category struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	ParentID  *int       `json:"parent_id"` // nil = kategori utama
+	BookCount int        `json:"book_count,omitempty"`
+	Children  []Category `json:"children,omitempty"` // Hanya terisi di GET /api/categories?tree=true
+}
+
+// Slugify mengubah nama menjadi slug URL: "Fiksi Ilmiah" -> "fiksi-ilmiah".
+// Dipakai juga untuk menyatukan kategori lama yang beda huruf besar/kecil ("Fiksi" & "fiksi").
+func Slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
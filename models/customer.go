@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Input registrasi customer (email sekaligus dipakai sebagai username login)
+type RegisterRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Password string `json:"password"`
+}
+
+// Alamat tersimpan milik customer (Tabel customer_addresses)
+type Address struct {
+	ID            int       `json:"id"`
+	UserID        int       `json:"user_id"`
+	Label         string    `json:"label"` // Contoh: Rumah, Kantor
+	RecipientName string    `json:"recipient_name"`
+	Phone         string    `json:"phone"`
+	Address       string    `json:"address"`
+	IsDefault     bool      `json:"is_default"`
+	CreatedAt     time.Time `json:"created_at"`
+}
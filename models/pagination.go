@@ -0,0 +1,16 @@
+package models
+
+// Info halaman untuk response list yang dipaginasi
+type PageMeta struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"` // Total data yang cocok dengan filter (semua halaman)
+	TotalPages int  `json:"total_pages"`
+	NextPage   *int `json:"next_page"` // nil jika sudah halaman terakhir
+}
+
+// Bentuk response list yang dipaginasi: { "data": [...], "meta": {...} }
+type PagedResponse struct {
+	Data interface{} `json:"data"`
+	Meta PageMeta    `json:"meta"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// Status pesanan (kolom transactions.status)
+const (
+	StatusPendingPayment = 100 // Menunggu pembayaran (status awal saat checkout)
+	StatusPaid           = 101 // Sudah dibayar
+	StatusProcessing     = 102 // Sedang dikemas
+	StatusShipped        = 103 // Dikirim
+	StatusCompleted      = 104 // Selesai / diterima
+	StatusCancelled      = 105 // Dibatalkan
+	StatusRefunded       = 106 // Dana dikembalikan
+)
+
+var statusNames = map[int]string{
+	StatusPendingPayment: "pending_payment",
+	StatusPaid:           "paid",
+	StatusProcessing:     "processing",
+	StatusShipped:        "shipped",
+	StatusCompleted:      "completed",
+	StatusCancelled:      "cancelled",
+	StatusRefunded:       "refunded",
+}
+
+// Perpindahan status yang diizinkan. Cancelled & Refunded adalah status akhir.
+var statusTransitions = map[int][]int{
+	StatusPendingPayment: {StatusPaid, StatusCancelled},
+	StatusPaid:           {StatusProcessing, StatusCancelled, StatusRefunded},
+	StatusProcessing:     {StatusShipped, StatusRefunded},
+	StatusShipped:        {StatusCompleted, StatusRefunded},
+	StatusCompleted:      {StatusRefunded},
+}
+
+// StatusName mengubah kode status menjadi nama, misal 101 -> "paid".
+func StatusName(status int) string {
+	if name, ok := statusNames[status]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// StatusFromName kebalikan dari StatusName, misal "paid" -> 101.
+func StatusFromName(name string) (int, bool) {
+	for status, n := range statusNames {
+		if n == name {
+			return status, true
+		}
+	}
+	return 0, false
+}
+
+// ValidStatus mengecek apakah kode status dikenal.
+func ValidStatus(status int) bool {
+	_, ok := statusNames[status]
+	return ok
+}
+
+// AllowedTransitions mengembalikan status tujuan yang boleh dari status sekarang.
+func AllowedTransitions(from int) []int {
+	return statusTransitions[from]
+}
+
+// CanTransition mengecek apakah status boleh berpindah dari 'from' ke 'to'.
+func CanTransition(from, to int) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Input admin untuk ubah status: { "status": 101 } atau { "status_name": "paid" }, note opsional
+type StatusUpdateRequest struct {
+	Status     int    `json:"status"`
+	StatusName string `json:"status_name"`
+	Note       string `json:"note"`
+}
+
+// Satu baris riwayat status (Tabel transaction_status_history)
+type StatusHistory struct {
+	ID             int       `json:"id"`
+	FromStatus     *int      `json:"from_status"` // nil untuk baris pertama (pesanan dibuat)
+	FromStatusName string    `json:"from_status_name,omitempty"`
+	ToStatus       int       `json:"to_status"`
+	ToStatusName   string    `json:"to_status_name"`
+	ChangedBy      *int      `json:"changed_by,omitempty"` // nil = sistem / customer tanpa login
+	ChangedByName  string    `json:"changed_by_name,omitempty"`
+	Note           string    `json:"note"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import "testing"
+
+var allStatuses = []int{
+	StatusPendingPayment, StatusPaid, StatusProcessing, StatusShipped,
+	StatusCompleted, StatusCancelled, StatusRefunded,
+}
+
+// Kontrak perpindahan status; semua pasangan lain harus ditolak (409 di API).
+var allowedMoves = map[[2]int]bool{
+	{StatusPendingPayment, StatusPaid}:      true,
+	{StatusPendingPayment, StatusCancelled}: true,
+	{StatusPaid, StatusProcessing}:          true,
+	{StatusPaid, StatusCancelled}:           true,
+	{StatusPaid, StatusRefunded}:            true,
+	{StatusProcessing, StatusShipped}:       true,
+	{StatusProcessing, StatusRefunded}:      true,
+	{StatusShipped, StatusCompleted}:        true,
+	{StatusShipped, StatusRefunded}:         true,
+	{StatusCompleted, StatusRefunded}:       true,
+}
+
+func TestCanTransitionEveryPair(t *testing.T) {
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			want := allowedMoves[[2]int{from, to}]
+			if got := CanTransition(from, to); got != want {
+				t.Errorf("CanTransition(%s, %s) = %v, want %v", StatusName(from), StatusName(to), got, want)
+			}
+		}
+	}
+}
+
+func TestFinalStatuses(t *testing.T) {
+	for _, final := range []int{StatusCancelled, StatusRefunded} {
+		if next := AllowedTransitions(final); len(next) != 0 {
+			t.Errorf("%s should be final, allows %v", StatusName(final), next)
+		}
+	}
+}
+
+// Pesanan yang sudah dikemas tidak bisa dibatalkan begitu saja (harus refund).
+func TestProcessingCannotBeCancelled(t *testing.T) {
+	if CanTransition(StatusProcessing, StatusCancelled) {
+		t.Error("processing -> cancelled should be rejected")
+	}
+}
+
+func TestUnknownStatus(t *testing.T) {
+	if CanTransition(999, StatusPaid) || CanTransition(StatusPendingPayment, 999) {
+		t.Error("transitions with unknown status should be rejected")
+	}
+	if ValidStatus(999) || StatusName(999) != "unknown" {
+		t.Error("999 should not be a valid status")
+	}
+}
+
+func TestStatusNameRoundTrip(t *testing.T) {
+	for _, status := range allStatuses {
+		if got, ok := StatusFromName(StatusName(status)); !ok || got != status {
+			t.Errorf("StatusFromName(%q) = %d, %v; want %d", StatusName(status), got, ok, status)
+		}
+	}
+}
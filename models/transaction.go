@@ -6,29 +6,34 @@ import "time"
 type Transaction struct {
 	ID            int     `json:"id"`
 	OrderCode     string  `json:"order_code"`
+	CustomerID    *int    `json:"customer_id,omitempty"` // Terisi jika checkout dalam keadaan login sebagai customer
+	AddressID     int     `json:"address_id,omitempty"`  // Opsional saat checkout: pakai alamat tersimpan
 	CustomerName  string  `json:"customer_name"`
 	CustomerEmail string  `json:"customer_email"` // <--- TAMBAHKAN INI
 	CustomerPhone string  `json:"customer_phone"`
 	Address       string  `json:"customer_address"`
 	PaymentMethod string  `json:"payment_method"`
 	TotalAmount   float64 `json:"total_amount"`
-	Status        int     `json:"status"`
-	
+	Status        int     `json:"status"`      // Lihat konstanta Status* di status.go
+	StatusName    string  `json:"status_name"` // Contoh: "pending_payment", "paid"
+
 	// Ubah Date jadi time.Time agar mudah di-scan
-	Date          time.Time `json:"date"` 
+	Date time.Time `json:"date"`
 
-	Details []TransactionDetail `json:"details"` 
+	Details []TransactionDetail `json:"details"`
+	History []StatusHistory     `json:"history,omitempty"` // Timeline status pesanan
 }
 
 // Detail Item (Tabel transaction_details)
 type TransactionDetail struct {
-	ID            int     `json:"id"`
-	TransactionID int     `json:"transaction_id"`
-	BookID        int     `json:"book_id"`
-	Quantity      int     `json:"quantity"`
-	Price         float64 `json:"price"` // Harga saat beli
-	Book struct {
-		Title string `json:"title"`
-		Image string `json:"image"`
+	ID            int      `json:"id"`
+	TransactionID int      `json:"transaction_id"`
+	BookID        int      `json:"book_id"`
+	Quantity      int      `json:"quantity"`
+	Price         float64  `json:"price"` // Harga saat beli
+	Book          struct { // Snapshot data buku saat beli
+		Title  string `json:"title"`
+		Author string `json:"author"`
+		Image  string `json:"image"`
 	} `json:"book"`
-}
\ No newline at end of file
+}
@@ -1,10 +1,23 @@
 package models
 
+import "time"
+
+// Role yang dikenal sistem (kolom users.role)
+const (
+	RoleAdmin    = "admin"
+	RoleCustomer = "customer"
+)
+
 type User struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"password"` // Nanti dikirim saat login
-	Role     string `json:"role"`
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	Password  string    `json:"password,omitempty"` // Nanti dikirim saat login / buat user, tidak pernah dikirim balik
+	Role      string    `json:"role"`
+	FullName  string    `json:"full_name,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Phone     string    `json:"phone,omitempty"`
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type LoginRequest struct {
@@ -13,9 +26,39 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Status  bool   `json:"status"`
-	Message string `json:"message"`
-	Token   string `json:"token"` // Token dummy
-	Role    string `json:"role"`
-	Username string `json:"username"`
-}
\ No newline at end of file
+	Status           bool      `json:"status"`
+	Message          string    `json:"message"`
+	Token            string    `json:"token"` // Access token (JWT), kirim via header Authorization: Bearer <token>
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"` // Untuk minta access token baru di /api/refresh
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	Role             string    `json:"role"`
+	Username         string    `json:"username"`
+}
+
+// Input untuk /api/refresh dan /api/logout
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Input admin untuk ubah user. Field yang tidak dikirim (nil) tidak diubah.
+type UpdateUserRequest struct {
+	Role     *string `json:"role"`
+	Disabled *bool   `json:"disabled"`
+}
+
+// Input untuk /api/password/forgot
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// Input untuk /api/password/reset (token dari link yang dikirim notifier)
+type ConfirmResetRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// Input admin untuk reset password user
+type PasswordResetRequest struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,49 @@
+package notifier
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Notifier mengirim pesan ke user (email, WhatsApp, dll).
+// Implementasi bisa diganti di main.go tanpa mengubah controller.
+type Notifier interface {
+	SendPasswordReset(email, name, resetLink string, expiresAt time.Time) error
+}
+
+// LogNotifier tidak benar-benar mengirim pesan, hanya menulisnya ke log
+// atau ke file. Dipakai untuk development dan testing.
+type LogNotifier struct {
+	FilePath string // Kosong = tulis ke log server saja
+
+	mu sync.Mutex
+}
+
+func NewLogNotifier(filePath string) *LogNotifier {
+	return &LogNotifier{FilePath: filePath}
+}
+
+func (n *LogNotifier) SendPasswordReset(email, name, resetLink string, expiresAt time.Time) error {
+	message := fmt.Sprintf("[%s] RESET PASSWORD -> %s (%s)\nLink: %s\nBerlaku sampai: %s\n\n",
+		time.Now().Format(time.RFC3339), email, name, resetLink, expiresAt.Format(time.RFC3339))
+
+	if n.FilePath == "" {
+		log.Print(message)
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(message)
+	return err
+}
@@ -0,0 +1,334 @@
+package search
+
+import (
+	"be/models"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Bobot setiap field buku saat menghitung relevansi
+const (
+	weightTitle       = 3.0
+	weightAuthor      = 2.5
+	weightCategory    = 1.5
+	weightDescription = 1.0
+)
+
+// Pengali skor berdasarkan jenis kecocokan kata
+const (
+	scoreExact  = 1.0
+	scorePrefix = 0.8
+	scoreTypo   = 0.6 // dikurangi lagi per huruf yang beda
+)
+
+// Hit adalah satu buku hasil pencarian beserta skornya
+type Hit struct {
+	BookID  int     `json:"book_id"`
+	Score   float64 `json:"score"`
+	matched int     // Jumlah kata query yang cocok
+}
+
+// Suggestion adalah saran autocomplete (judul atau nama penulis)
+type Suggestion struct {
+	Text   string `json:"text"`
+	Type   string `json:"type"` // "title" atau "author"
+	BookID int    `json:"book_id"`
+}
+
+type document struct {
+	title  string
+	author string
+	terms  map[string]float64 // kata -> bobot (jumlah bobot field tempat kata muncul)
+}
+
+// Index adalah inverted index in-memory untuk buku (kata -> buku yang mengandung kata tersebut).
+// Aman dipakai dari banyak goroutine.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[int]*document
+	postings map[string]map[int]float64 // kata -> book ID -> bobot
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[int]*document),
+		postings: make(map[string]map[int]float64),
+	}
+}
+
+// Add memasukkan / memperbarui buku di index.
+func (idx *Index) Add(book models.Book) {
+	doc := &document{
+		title:  book.Title,
+		author: book.Author,
+		terms:  make(map[string]float64),
+	}
+	addTerms(doc.terms, book.Title, weightTitle)
+	addTerms(doc.terms, book.Author, weightAuthor)
+	// Buku bisa punya beberapa kategori; jika belum dimuat, pakai kategori utama
+	if len(book.Categories) > 0 {
+		for _, c := range book.Categories {
+			addTerms(doc.terms, c.Name, weightCategory)
+		}
+	} else {
+		addTerms(doc.terms, book.Category, weightCategory)
+	}
+	addTerms(doc.terms, book.Description, weightDescription)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(book.ID)
+	idx.docs[book.ID] = doc
+	for term, weight := range doc.terms {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[int]float64)
+		}
+		idx.postings[term][book.ID] = weight
+	}
+}
+
+// Remove menghapus buku dari index.
+func (idx *Index) Remove(bookID int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(bookID)
+}
+
+func (idx *Index) remove(bookID int) {
+	doc, ok := idx.docs[bookID]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		delete(idx.postings[term], bookID)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.docs, bookID)
+}
+
+// Len mengembalikan jumlah buku di index.
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.docs)
+}
+
+// Search mencari buku yang relevan dengan query, diurutkan dari yang paling relevan.
+// Setiap kata query dicocokkan secara persis, sebagai awalan kata (prefix), atau dengan
+// toleransi salah ketik. Buku yang cocok dengan lebih banyak kata query selalu di atas.
+func (idx *Index) Search(query string) []Hit {
+	queryTerms := tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	totalDocs := float64(len(idx.docs))
+	hits := map[int]*Hit{}
+
+	for _, qt := range queryTerms {
+		// Skor terbaik kata query ini untuk setiap buku
+		best := map[int]float64{}
+
+		for term, postings := range idx.postings {
+			match := matchScore(qt, term)
+			if match == 0 {
+				continue
+			}
+
+			// IDF: kata yang jarang muncul lebih berarti
+			idf := math.Log(1 + totalDocs/float64(len(postings)))
+			for bookID, weight := range postings {
+				if score := match * weight * idf; score > best[bookID] {
+					best[bookID] = score
+				}
+			}
+		}
+
+		for bookID, score := range best {
+			hit, ok := hits[bookID]
+			if !ok {
+				hit = &Hit{BookID: bookID}
+				hits[bookID] = hit
+			}
+			hit.Score += score
+			hit.matched++
+		}
+	}
+
+	results := make([]Hit, 0, len(hits))
+	for _, hit := range hits {
+		hit.Score = math.Round(hit.Score*1000) / 1000
+		results = append(results, *hit)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].matched != results[j].matched {
+			return results[i].matched > results[j].matched
+		}
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].BookID > results[j].BookID
+	})
+	return results
+}
+
+// Suggest memberi saran judul / penulis untuk autocomplete berdasarkan teks yang sedang diketik.
+func (idx *Index) Suggest(prefix string, limit int) []Suggestion {
+	hits := idx.Search(prefix)
+	queryTerms := tokenize(prefix)
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	suggestions := []Suggestion{}
+	seen := map[string]bool{}
+
+	add := func(text, kind string, bookID int) {
+		key := kind + ":" + strings.ToLower(text)
+		if text == "" || seen[key] || len(suggestions) >= limit {
+			return
+		}
+		seen[key] = true
+		suggestions = append(suggestions, Suggestion{Text: text, Type: kind, BookID: bookID})
+	}
+
+	for _, hit := range hits {
+		// Hanya buku yang cocok dengan semua kata yang diketik
+		if hit.matched < len(queryTerms) {
+			break
+		}
+		doc, ok := idx.docs[hit.BookID]
+		if !ok {
+			continue // Baru saja dihapus dari index
+		}
+		if containsAllTerms(doc.author, queryTerms) {
+			add(doc.author, "author", hit.BookID)
+		}
+		if containsAllTerms(doc.title, queryTerms) {
+			add(doc.title, "title", hit.BookID)
+		}
+	}
+	return suggestions
+}
+
+// containsAllTerms mengecek apakah setiap kata query cocok dengan salah satu kata di teks.
+func containsAllTerms(text string, queryTerms []string) bool {
+	words := tokenize(text)
+	for _, qt := range queryTerms {
+		found := false
+		for _, w := range words {
+			if matchScore(qt, w) > 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// matchScore membandingkan kata query dengan kata di index. 0 berarti tidak cocok.
+func matchScore(query, term string) float64 {
+	if query == term {
+		return scoreExact
+	}
+	if len(query) >= 2 && strings.HasPrefix(term, query) {
+		return scorePrefix
+	}
+
+	maxEdits := allowedTypos(query)
+	if maxEdits == 0 {
+		return 0
+	}
+	if dist := editDistance(query, term, maxEdits); dist <= maxEdits {
+		return scoreTypo / float64(dist)
+	}
+	return 0
+}
+
+// allowedTypos: kata pendek harus persis, kata lebih panjang boleh salah 1-2 huruf.
+func allowedTypos(term string) int {
+	n := len([]rune(term))
+	switch {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance menghitung jumlah edit antara a dan b: sisip, hapus, ganti huruf, atau tukar dua huruf
+// yang bersebelahan ("andera" -> "andrea" = 1 edit). Ini jarak optimal string alignment (Damerau),
+// karena huruf tertukar adalah salah ketik yang paling sering.
+// Berhenti lebih awal dan mengembalikan max+1 jika jaraknya sudah pasti lebih dari max.
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > max || -diff > max {
+		return max + 1
+	}
+
+	prevPrev := make([]int, len(rb)+1) // Baris i-2, untuk huruf tertukar
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	prevMin := 0
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prevPrev[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		// Baris berikutnya tidak bisa lebih kecil dari ini (tukar huruf melompati satu baris, +1)
+		if min(rowMin, prevMin+1) > max {
+			return max + 1
+		}
+		prevMin = rowMin
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+	return prev[len(rb)]
+}
+
+// tokenize memecah teks menjadi kata huruf kecil (huruf & angka saja).
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func addTerms(terms map[string]float64, text string, weight float64) {
+	seen := map[string]bool{}
+	for _, term := range tokenize(text) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms[term] += weight
+	}
+}
@@ -0,0 +1,173 @@
+package search
+
+import (
+	"be/models"
+	"testing"
+)
+
+func sampleIndex() *Index {
+	idx := NewIndex()
+	for _, b := range []models.Book{
+		{ID: 1, Title: "Laskar Pelangi", Author: "Andrea Hirata", Category: "Fiksi", Description: "Kisah sepuluh anak di Belitung"},
+		{ID: 2, Title: "Bumi", Author: "Tere Liye", Category: "Fantasi", Description: "Petualangan Raib di dunia paralel"},
+		{ID: 3, Title: "Bumi Manusia", Author: "Pramoedya Ananta Toer", Category: "Sejarah", Description: "Minke dan bumi Hindia"},
+		{ID: 4, Title: "Harry Potter dan Batu Bertuah", Author: "J.K. Rowling", Category: "Fantasi", Description: "Penyihir muda"},
+	} {
+		idx.Add(b)
+	}
+	return idx
+}
+
+func hitIDs(hits []Hit) []int {
+	ids := make([]int, len(hits))
+	for i, h := range hits {
+		ids[i] = h.BookID
+	}
+	return ids
+}
+
+func TestSearchMatches(t *testing.T) {
+	idx := sampleIndex()
+
+	tests := []struct {
+		name, query string
+		want        int // Buku teratas yang diharapkan, 0 = tidak ada hasil
+	}{
+		{"exact", "laskar", 1},
+		{"case insensitive", "PELANGI", 1},
+		{"prefix", "lask", 1},
+		{"typo", "pelangu", 1},
+		{"swapped letters", "andera", 1},
+		{"swapped letters at start", "hriata", 1},
+		{"swapped letters at end", "rowlign", 4},
+		{"short word must be exact", "bmi", 0},
+		{"too many typos", "xyzabc", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hits := idx.Search(tc.query)
+			if tc.want == 0 {
+				if len(hits) != 0 {
+					t.Errorf("Search(%q) = %v, want no hits", tc.query, hitIDs(hits))
+				}
+				return
+			}
+			if len(hits) == 0 || hits[0].BookID != tc.want {
+				t.Errorf("Search(%q) = %v, want book %d first", tc.query, hitIDs(hits), tc.want)
+			}
+		})
+	}
+}
+
+// Exact > prefix > typo untuk kata yang sama
+func TestSearchScoresExactAbovePrefixAboveTypo(t *testing.T) {
+	idx := sampleIndex()
+
+	exact := idx.Search("pelangi")[0].Score
+	prefix := idx.Search("pelang")[0].Score
+	typo := idx.Search("pelangu")[0].Score
+	if !(exact > prefix && prefix > typo) {
+		t.Errorf("scores exact=%v prefix=%v typo=%v, want exact > prefix > typo", exact, prefix, typo)
+	}
+}
+
+// Buku yang cocok dengan lebih banyak kata query selalu di atas, walau skor satu katanya lebih kecil.
+func TestSearchRanksByMatchedWords(t *testing.T) {
+	idx := sampleIndex()
+
+	hits := idx.Search("bumi liye")
+	if got := hitIDs(hits); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("Search(bumi liye) = %v, want [2 3]", got)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	idx := sampleIndex()
+
+	idx.Remove(1)
+	if hits := idx.Search("laskar"); len(hits) != 0 {
+		t.Errorf("removed book still found: %v", hitIDs(hits))
+	}
+	if idx.Len() != 3 {
+		t.Errorf("Len = %d, want 3", idx.Len())
+	}
+
+	// Buku lain dengan kata yang sama tetap ada
+	idx.Remove(2)
+	if got := hitIDs(idx.Search("bumi")); len(got) != 1 || got[0] != 3 {
+		t.Errorf("Search(bumi) = %v, want [3]", got)
+	}
+
+	idx.Remove(99) // Tidak ada di index: tidak apa-apa
+}
+
+// Add ulang buku yang sama mengganti isinya, bukan menambah
+func TestAddReplacesBook(t *testing.T) {
+	idx := sampleIndex()
+
+	idx.Add(models.Book{ID: 1, Title: "Sang Pemimpi", Author: "Andrea Hirata"})
+	if hits := idx.Search("laskar"); len(hits) != 0 {
+		t.Errorf("old title still found: %v", hitIDs(hits))
+	}
+	if got := hitIDs(idx.Search("pemimpi")); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Search(pemimpi) = %v, want [1]", got)
+	}
+	if idx.Len() != 4 {
+		t.Errorf("Len = %d, want 4", idx.Len())
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	idx := sampleIndex()
+
+	got := idx.Suggest("andrea hir", 5)
+	if len(got) != 1 || got[0] != (Suggestion{Text: "Andrea Hirata", Type: "author", BookID: 1}) {
+		t.Errorf("Suggest(andrea hir) = %+v", got)
+	}
+
+	got = idx.Suggest("laskar pel", 5)
+	if len(got) != 1 || got[0] != (Suggestion{Text: "Laskar Pelangi", Type: "title", BookID: 1}) {
+		t.Errorf("Suggest(laskar pel) = %+v", got)
+	}
+
+	// Salah ketik pada nama penulis tetap memberi saran
+	got = idx.Suggest("rowlign", 5)
+	if len(got) != 1 || got[0].Text != "J.K. Rowling" {
+		t.Errorf("Suggest(rowlign) = %+v", got)
+	}
+
+	// Judul yang sama dari beberapa buku hanya muncul sekali, dan limit dipatuhi
+	if got := idx.Suggest("bumi", 5); len(got) != 2 {
+		t.Errorf("Suggest(bumi) = %+v, want 2 titles", got)
+	}
+	if got := idx.Suggest("bumi", 1); len(got) != 1 {
+		t.Errorf("Suggest(bumi, 1) = %+v, want 1", got)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"hirata", "hirata", 2, 0},
+		{"hirata", "hirta", 2, 1},   // hapus
+		{"hirata", "hiraata", 2, 1}, // sisip
+		{"hirata", "hirota", 2, 1},  // ganti
+		{"andera", "andrea", 2, 1},  // tukar
+		{"rowlign", "rowling", 2, 1},
+		{"hriata", "hirata", 2, 1},
+		{"ab", "ba", 2, 1},
+		{"abcd", "badc", 2, 2},     // dua pasang tertukar
+		{"kisah", "sejarah", 2, 3}, // melebihi max -> max+1
+		{"a", "abcdef", 1, 2},
+	}
+
+	for _, tc := range tests {
+		if got := editDistance(tc.a, tc.b, tc.max); got != tc.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tc.a, tc.b, tc.max, got, tc.want)
+		}
+	}
+}
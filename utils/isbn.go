@@ -0,0 +1,67 @@
+package utils
+
+import "strings"
+
+// NormalizeISBN merapikan input ISBN-10 / ISBN-13 (boleh pakai tanda hubung atau spasi),
+// mengecek checksum-nya, lalu mengembalikan bentuk ISBN-13 tanpa tanda hubung.
+// ISBN-10 selalu dikonversi ke ISBN-13 (awalan 978) agar satu buku hanya punya satu nilai
+// di database, sehingga UNIQUE index tetap menangkap buku yang sama walau diinput beda format.
+func NormalizeISBN(isbn string) (string, bool) {
+	isbn = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(isbn)))
+
+	switch len(isbn) {
+	case 10:
+		if !validISBN10(isbn) {
+			return "", false
+		}
+		body := "978" + isbn[:9]
+		return body + string(isbn13CheckDigit(body)), true
+	case 13:
+		if !isDigits(isbn) || isbn13CheckDigit(isbn[:12]) != isbn[12] {
+			return "", false
+		}
+		return isbn, true
+	}
+	return "", false
+}
+
+// validISBN10: jumlah digit x bobot (10..1) harus habis dibagi 11, digit terakhir boleh X (= 10)
+func validISBN10(isbn string) bool {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		c := isbn[i]
+		var v int
+		switch {
+		case c >= '0' && c <= '9':
+			v = int(c - '0')
+		case c == 'X' && i == 9:
+			v = 10
+		default:
+			return false
+		}
+		sum += v * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+// isbn13CheckDigit menghitung check digit dari 12 digit pertama (bobot bergantian 1 dan 3)
+func isbn13CheckDigit(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		v := int(body[i] - '0')
+		if i%2 == 1 {
+			v *= 3
+		}
+		sum += v
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package utils
+
+import "testing"
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+		ok                bool
+	}{
+		// Valid
+		{"isbn-13", "9780306406157", "9780306406157", true},
+		{"isbn-13 with hyphens", "978-0-306-40615-7", "9780306406157", true},
+		{"isbn-13 with spaces", " 978 0 306 40615 7 ", "9780306406157", true},
+		{"isbn-10 converted to 13", "0306406152", "9780306406157", true},
+		{"isbn-10 with hyphens", "0-306-40615-2", "9780306406157", true},
+		{"isbn-10 check digit X", "0-8044-2957-X", "9780804429573", true},
+		{"isbn-10 lowercase x", "080442957x", "9780804429573", true},
+		{"isbn-13 979 prefix", "979-10-90636-07-1", "9791090636071", true},
+
+		// Tidak valid
+		{"empty", "", "", false},
+		{"isbn-13 bad checksum", "9780306406158", "", false},
+		{"isbn-10 bad checksum", "0306406153", "", false},
+		{"too short", "030640615", "", false},
+		{"too long", "97803064061570", "", false},
+		{"eleven digits", "03064061522", "", false},
+		{"X not last", "0X06406152", "", false},
+		{"X in isbn-13", "978030640615X", "", false},
+		{"letters", "ABCDEFGHIJ", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := NormalizeISBN(tc.input)
+			if got != tc.want || ok != tc.ok {
+				t.Errorf("NormalizeISBN(%q) = %q, %v; want %q, %v", tc.input, got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+// ISBN-10 dan ISBN-13 dari buku yang sama harus jadi satu nilai (agar UNIQUE index menangkapnya).
+func TestNormalizeISBNTenAndThirteenMatch(t *testing.T) {
+	pairs := [][2]string{
+		{"0306406152", "978-0-306-40615-7"},
+		{"080442957X", "9780804429573"},
+		{"979-3062-79-7", "978-979-3062-79-2"},
+	}
+	for _, p := range pairs {
+		ten, ok10 := NormalizeISBN(p[0])
+		thirteen, ok13 := NormalizeISBN(p[1])
+		if !ok10 || !ok13 || ten != thirteen {
+			t.Errorf("NormalizeISBN(%q) = %q, %v; NormalizeISBN(%q) = %q, %v; want same valid value", p[0], ten, ok10, p[1], thirteen, ok13)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// LoginGuard menghitung login gagal per key (username atau IP) dan
+// mengunci sementara dengan jeda yang naik eksponensial setiap kali gagal lagi.
+// Data disimpan di memory, jadi ter-reset saat server restart.
+type LoginGuard struct {
+	Threshold   int           // Jumlah gagal sebelum mulai dikunci
+	BaseDelay   time.Duration // Lama kunci pertama, lalu dikali 2 setiap gagal berikutnya
+	MaxDelay    time.Duration // Batas maksimal lama kunci
+	ForgetAfter time.Duration // Counter dihapus jika tidak ada kegagalan selama ini
+
+	Now func() time.Time // Bisa diganti untuk testing
+
+	mu      sync.Mutex
+	entries map[string]*loginAttempt
+}
+
+type loginAttempt struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+func NewLoginGuard(threshold int, baseDelay, maxDelay time.Duration) *LoginGuard {
+	return &LoginGuard{
+		Threshold:   threshold,
+		BaseDelay:   baseDelay,
+		MaxDelay:    maxDelay,
+		ForgetAfter: 24 * time.Hour,
+		Now:         time.Now,
+		entries:     make(map[string]*loginAttempt),
+	}
+}
+
+// Attempt dipanggil SEBELUM password dicek. Jika key sedang dikunci, mengembalikan sisa waktu
+// kunci (wait) dan percobaan tidak dihitung. Jika tidak, percobaan langsung dihitung sebagai gagal
+// dalam satu lock yang sama, sehingga request paralel tidak bisa lolos semua selama bcrypt berjalan.
+// lock adalah lama kunci yang dipicu percobaan ini (nol jika belum dikunci).
+// Jika password ternyata benar, panggil Reset atau Cancel.
+func (g *LoginGuard) Attempt(key string) (wait, lock time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.Now()
+	g.prune(now)
+
+	entry, ok := g.entries[key]
+	if !ok || now.Sub(entry.lastFailure) > g.ForgetAfter {
+		entry = &loginAttempt{}
+		g.entries[key] = entry
+	}
+
+	if now.Before(entry.lockedUntil) {
+		return entry.lockedUntil.Sub(now), 0
+	}
+
+	entry.failures++
+	entry.lastFailure = now
+
+	if entry.failures < g.Threshold {
+		return 0, 0
+	}
+
+	// Gagal ke-Threshold: BaseDelay, berikutnya 2x, 4x, ... sampai MaxDelay
+	delay := g.BaseDelay
+	for i := g.Threshold; i < entry.failures && delay < g.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > g.MaxDelay {
+		delay = g.MaxDelay
+	}
+
+	entry.lockedUntil = now.Add(delay)
+	return 0, delay
+}
+
+// Cancel membatalkan satu percobaan yang sudah dihitung Attempt (password benar, atau error server).
+// Kunci dilepas jika jumlah gagal kembali di bawah Threshold.
+func (g *LoginGuard) Cancel(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	entry, ok := g.entries[key]
+	if !ok {
+		return
+	}
+
+	entry.failures--
+	if entry.failures <= 0 {
+		delete(g.entries, key)
+		return
+	}
+	if entry.failures < g.Threshold {
+		entry.lockedUntil = time.Time{}
+	}
+}
+
+// Reset menghapus counter key (setelah login sukses atau di-unlock admin).
+func (g *LoginGuard) Reset(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.entries, key)
+}
+
+// prune membersihkan entry lama agar map tidak terus membesar.
+func (g *LoginGuard) prune(now time.Time) {
+	if len(g.entries) < 10000 {
+		return
+	}
+	for key, entry := range g.entries {
+		if now.Sub(entry.lastFailure) > g.ForgetAfter && now.After(entry.lockedUntil) {
+			delete(g.entries, key)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// Request paralel (bcrypt berjalan di antara cek & catat gagal) tidak boleh melewati Threshold.
+func TestLoginGuardAttemptIsAtomic(t *testing.T) {
+	guard := NewLoginGuard(5, 30*time.Second, 15*time.Minute)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if wait, _ := guard.Attempt("budi"); wait == 0 {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 5 {
+		t.Fatalf("allowed = %d attempts, want 5", allowed)
+	}
+}
+
+func TestLoginGuardLockAndCancel(t *testing.T) {
+	now := time.Date(2025, 1, 30, 10, 0, 0, 0, time.UTC)
+	guard := NewLoginGuard(3, 30*time.Second, 2*time.Minute)
+	guard.Now = func() time.Time { return now }
+
+	for i := 1; i <= 2; i++ {
+		if wait, lock := guard.Attempt("budi"); wait != 0 || lock != 0 {
+			t.Fatalf("attempt %d: wait=%v lock=%v, want no lock", i, wait, lock)
+		}
+	}
+
+	// Percobaan ke-3 memicu kunci 30 detik
+	if _, lock := guard.Attempt("budi"); lock != 30*time.Second {
+		t.Fatalf("lock = %v, want 30s", lock)
+	}
+	if wait, _ := guard.Attempt("budi"); wait != 30*time.Second {
+		t.Fatalf("wait = %v, want 30s", wait)
+	}
+
+	// Setelah kunci habis, gagal lagi -> kunci 2x lebih lama
+	now = now.Add(31 * time.Second)
+	if _, lock := guard.Attempt("budi"); lock != time.Minute {
+		t.Fatalf("lock = %v, want 1m", lock)
+	}
+
+	// Password benar: percobaan dibatalkan, tapi masih di atas Threshold jadi tetap terkunci
+	guard.Cancel("budi")
+	if wait, _ := guard.Attempt("budi"); wait == 0 {
+		t.Fatal("expected key to stay locked after cancelling one of 4 failures")
+	}
+
+	// Di bawah Threshold kunci dilepas
+	guard.Cancel("budi")
+	if wait, _ := guard.Attempt("budi"); wait != 0 {
+		t.Fatalf("wait = %v, want unlocked", wait)
+	}
+
+	guard.Reset("budi")
+	if wait, lock := guard.Attempt("budi"); wait != 0 || lock != 0 {
+		t.Fatalf("after reset: wait=%v lock=%v", wait, lock)
+	}
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"crypto/rand"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Alfabet Crockford Base32: tanpa I, L, O, U agar tidak tertukar saat dibaca/diketik
+const orderCodeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Format: B3-YYYYMMDD-XXXX-XXXC (7 karakter acak + 1 check digit)
+var orderCodePattern = regexp.MustCompile(`^B3-(\d{8})-([0-9A-Z]{4})-([0-9A-Z]{4})$`)
+
+// GenerateOrderCode membuat kode pesanan acak (crypto/rand) dengan check digit.
+// Contoh: B3-20250130-K7M3-QX9A
+func GenerateOrderCode(now time.Time) (string, error) {
+	random := make([]byte, 7)
+	max := big.NewInt(int64(len(orderCodeAlphabet)))
+	for i := range random {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		random[i] = orderCodeAlphabet[n.Int64()]
+	}
+
+	date := now.Format("20060102")
+	body := string(random)
+	check := orderCodeCheckChar(date + body)
+
+	return "B3-" + date + "-" + body[:4] + "-" + body[4:] + string(check), nil
+}
+
+// NormalizeOrderCode merapikan input user: huruf besar, tanpa spasi,
+// dan huruf yang mirip angka (O, I, L) diubah ke angkanya.
+func NormalizeOrderCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, " ", "")
+	if !strings.HasPrefix(code, "B3-") {
+		return code
+	}
+	rest := strings.NewReplacer("O", "0", "I", "1", "L", "1").Replace(code[3:])
+	return "B3-" + rest
+}
+
+// IsNewOrderCodeFormat bernilai true jika kode memakai format baru (dengan check digit).
+// Kode lama (B3-YYYYMMDD-NNNN) tetap bisa dicari tapi tidak punya check digit.
+func IsNewOrderCodeFormat(code string) bool {
+	return orderCodePattern.MatchString(code)
+}
+
+// ValidOrderCode mengecek check digit kode format baru, untuk menangkap salah ketik
+// sebelum query ke database.
+func ValidOrderCode(code string) bool {
+	m := orderCodePattern.FindStringSubmatch(code)
+	if m == nil {
+		return false
+	}
+
+	body := m[2] + m[3]
+	for _, c := range body {
+		if !strings.ContainsRune(orderCodeAlphabet, c) {
+			return false
+		}
+	}
+
+	payload := m[1] + body[:7]
+	return orderCodeCheckChar(payload) == body[7]
+}
+
+// orderCodeCheckChar menghitung check digit dengan algoritma Luhn mod 32,
+// yang menangkap salah ketik satu karakter dan tertukarnya dua karakter bersebelahan
+// (kecuali pasangan 0 dan Z, keterbatasan bawaan Luhn mod N).
+func orderCodeCheckChar(payload string) byte {
+	n := len(orderCodeAlphabet)
+	factor := 2
+	sum := 0
+
+	for i := len(payload) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(orderCodeAlphabet, payload[i])
+		factor = 3 - factor // bergantian 2, 1, 2, 1, ...
+		sum += addend/n + addend%n
+	}
+
+	return orderCodeAlphabet[(n-sum%n)%n]
+}
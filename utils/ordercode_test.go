@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// orderCodeFromBody menyusun kode format baru dari 7 karakter acak tetap (untuk test yang deterministik).
+func orderCodeFromBody(date, body string) string {
+	return "B3-" + date + "-" + body[:4] + "-" + body[4:] + string(orderCodeCheckChar(date+body))
+}
+
+func TestGenerateOrderCodeIsValid(t *testing.T) {
+	now := time.Date(2025, 1, 30, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 200; i++ {
+		code, err := GenerateOrderCode(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(code, "B3-20250130-") || !IsNewOrderCodeFormat(code) || !ValidOrderCode(code) {
+			t.Fatalf("generated code %q is not valid", code)
+		}
+		if NormalizeOrderCode(code) != code {
+			t.Fatalf("NormalizeOrderCode(%q) changed a generated code", code)
+		}
+	}
+}
+
+// Salah ketik satu karakter di posisi mana pun harus tertangkap check digit.
+func TestValidOrderCodeRejectsSingleCharacterTypo(t *testing.T) {
+	for _, code := range []string{
+		orderCodeFromBody("20250130", "K7M3QX9"),
+		orderCodeFromBody("20241231", "0000000"),
+		orderCodeFromBody("20250101", "ZZZZZZZ"),
+	} {
+		for i := range code {
+			for _, c := range orderCodeAlphabet + "-" {
+				if byte(c) == code[i] {
+					continue
+				}
+				typo := code[:i] + string(c) + code[i+1:]
+				if ValidOrderCode(typo) {
+					t.Errorf("typo %q of %q passes validation", typo, code)
+				}
+			}
+		}
+	}
+}
+
+// Dua karakter bersebelahan yang tertukar harus tertangkap. Satu-satunya pengecualian
+// adalah pasangan 0 dan Z: keterbatasan Luhn mod N (sama seperti 09/90 pada Luhn desimal).
+func TestValidOrderCodeRejectsAdjacentSwap(t *testing.T) {
+	for _, code := range []string{
+		orderCodeFromBody("20250130", "K7M3QX9"),
+		orderCodeFromBody("20241231", "ABCDEFG"),
+		orderCodeFromBody("20250101", "9Y8X7W6"),
+	} {
+		for i := 0; i+1 < len(code); i++ {
+			a, b := code[i], code[i+1]
+			if a == b || (a == '0' && b == 'Z') || (a == 'Z' && b == '0') {
+				continue
+			}
+			swapped := code[:i] + string(b) + string(a) + code[i+2:]
+			if ValidOrderCode(swapped) {
+				t.Errorf("swap %q of %q passes validation", swapped, code)
+			}
+		}
+	}
+}
+
+func TestNormalizeOrderCode(t *testing.T) {
+	code := orderCodeFromBody("20250130", "K0M1QX1")
+
+	// Huruf kecil, spasi, dan O/I/L yang diketik sebagai ganti 0/1
+	typed := strings.NewReplacer("0", "o", "1", "l").Replace(strings.ToLower(code))
+	typed = " " + typed[:12] + " " + typed[12:] + " "
+	if got := NormalizeOrderCode(typed); got != code {
+		t.Errorf("NormalizeOrderCode(%q) = %q, want %q", typed, got, code)
+	}
+	if got := NormalizeOrderCode(strings.ReplaceAll(code, "1", "I")); got != code {
+		t.Errorf("NormalizeOrderCode with I = %q, want %q", got, code)
+	}
+	if !ValidOrderCode(NormalizeOrderCode(typed)) {
+		t.Errorf("normalized code %q is not valid", NormalizeOrderCode(typed))
+	}
+}
+
+// Kode lama (tanpa check digit) dan kode hasil perbaikan duplikat (akhiran _id)
+// tetap dicari apa adanya: tidak diubah dan tidak dianggap format baru.
+func TestLegacyOrderCodesPassThrough(t *testing.T) {
+	for _, code := range []string{
+		"B3-20250101-0001",
+		"B3-20250101-0001_2",
+		orderCodeFromBody("20250130", "K7M3QX9") + "_15",
+	} {
+		if got := NormalizeOrderCode(code); got != code {
+			t.Errorf("NormalizeOrderCode(%q) = %q, want unchanged", code, got)
+		}
+		if IsNewOrderCodeFormat(code) {
+			t.Errorf("IsNewOrderCodeFormat(%q) = true, want false", code)
+		}
+	}
+}
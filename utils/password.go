@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cost bcrypt (semakin besar semakin lambat & aman)
+const passwordCost = 12
+
+// Hash dummy untuk menyamakan waktu proses saat username tidak ditemukan
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), passwordCost)
+
+// HashPassword mengubah password plain text menjadi hash bcrypt.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// IsHashed mengecek apakah password di database sudah berupa hash bcrypt.
+func IsHashed(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") || strings.HasPrefix(stored, "$2y$")
+}
+
+// CheckPassword membandingkan password input dengan yang tersimpan di database.
+// needsRehash bernilai true jika yang tersimpan masih plain text (data lama)
+// atau cost-nya sudah ketinggalan, sehingga perlu di-hash ulang setelah login sukses.
+func CheckPassword(stored, password string) (ok bool, needsRehash bool) {
+	if !IsHashed(stored) {
+		// Data lama: masih plain text
+		ok = subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+		return ok, ok
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)); err != nil {
+		return false, false
+	}
+
+	cost, err := bcrypt.Cost([]byte(stored))
+	return true, err == nil && cost < passwordCost
+}
+
+// DummyCheckPassword dipanggil saat user tidak ditemukan,
+// agar waktu response tidak membocorkan username mana yang terdaftar.
+func DummyCheckPassword(password string) {
+	bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
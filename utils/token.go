@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"be/config"
+	"be/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Isi (payload) access token
+type Claims struct {
+	UserID    int    `json:"uid"`
+	Role      string `json:"role"`
+	SessionID int    `json:"sid"` // ID refresh token (sesi) yang menerbitkan access token ini
+	jwt.RegisteredClaims
+}
+
+// GenerateToken membuat access token bertanda tangan HMAC untuk user yang berhasil login.
+func GenerateToken(user models.User, sessionID int) (string, time.Time, error) {
+	if len(config.JWTSecret) == 0 {
+		return "", time.Time{}, errors.New("JWT secret belum diatur")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(config.AccessTokenTTL)
+
+	claims := Claims{
+		UserID:    user.ID,
+		Role:      user.Role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(user.ID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(config.JWTSecret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseToken memvalidasi tanda tangan & masa berlaku token, lalu mengembalikan isinya.
+func ParseToken(tokenStr string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		if len(config.JWTSecret) == 0 {
+			return nil, errors.New("JWT secret belum diatur")
+		}
+		return config.JWTSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// NewRandomToken membuat token acak (refresh token, reset password) untuk dikirim ke client
+// beserta hash-nya untuk disimpan di DB.
+func NewRandomToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken menghasilkan SHA-256 (hex) dari token. Token asli tidak pernah disimpan di DB.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}